@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+)
+
+// adminKey is the context key for the name of the admin making a request
+type adminKey struct{}
+
+// requestAdmin returns the name of the admin whose token authenticated the
+// request, or "" if no token was needed
+func requestAdmin(ctx context.Context) string {
+	name, _ := ctx.Value(adminKey{}).(string)
+	return name
+}
+
+// middlewareAdmin only lets admins call next. With ADMIN_TOKENS set, a
+// request needs one of the tokens, as a bearer token or as the password of
+// basic auth so a browser can open the dashboard. Without tokens the admin
+// endpoints are open in dev and demo mode and closed in prod.
+func (cfg *apiConfig) middlewareAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(cfg.adminTokens) == 0 {
+			if cfg.platform == config.PlatformProd {
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(errorResponse{Error: "Admin endpoints need ADMIN_TOKENS in prod mode"})
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name, ok := cfg.authenticateAdmin(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Chirpy admin"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Invalid admin token"})
+			return
+		}
+		ctx := context.WithValue(r.Context(), adminKey{}, name)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticateAdmin returns the name of the admin whose token the request
+// carries
+func (cfg *apiConfig) authenticateAdmin(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, _ = r.BasicAuth()
+	}
+	return cfg.adminForToken(token)
+}
+
+// adminForToken returns the name of the admin token is for. Every token is
+// compared so the time taken doesn't tell which one nearly matched.
+func (cfg *apiConfig) adminForToken(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	var admin string
+	for name, want := range cfg.adminTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			admin = name
+		}
+	}
+	return admin, admin != ""
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/jobs"
+)
+
+// recentFailedJobs is how many failed jobs /admin/jobs lists
+const recentFailedJobs = 20
+
+// failedJobResponse represents a job that ran out of attempts
+type failedJobResponse struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Attempts  int32     `json:"attempts"`
+	LastError string    `json:"last_error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// jobsResponse represents the job queue status
+type jobsResponse struct {
+	Depth    int64               `json:"depth"`
+	Counts   map[string]int64    `json:"counts"`
+	Failures []failedJobResponse `json:"failures"`
+}
+
+// jobsHandler reports the job queue depth and recent failures
+func (cfg *apiConfig) jobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenant := tenantID(r.Context())
+	counts, err := cfg.db.GetJobCounts(r.Context(), tenant)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to count jobs"})
+		return
+	}
+	failed, err := cfg.db.ListFailedJobs(r.Context(), database.ListFailedJobsParams{
+		TenantID: tenant,
+		Limit:    recentFailedJobs,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list failed jobs"})
+		return
+	}
+
+	// Depth is everything not yet finished
+	resp := jobsResponse{
+		Counts:   map[string]int64{},
+		Failures: []failedJobResponse{},
+	}
+	for _, row := range counts {
+		resp.Counts[row.Status] = row.Count
+		if row.Status == jobs.StatusPending || row.Status == jobs.StatusRunning {
+			resp.Depth += row.Count
+		}
+	}
+	for _, job := range failed {
+		resp.Failures = append(resp.Failures, failedJobResponse{
+			ID:        job.ID.String(),
+			Kind:      job.Kind,
+			Attempts:  job.Attempts,
+			LastError: job.LastError,
+			FailedAt:  job.UpdatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
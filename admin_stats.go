@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/metrics"
+	"github.com/hydeh3r3/chirpy/internal/retry"
+)
+
+// Dashboard windows
+const (
+	statsDays       = 30
+	statsTopAuthors = 10
+)
+
+// topAuthor is a user ranked by recent chirps
+type topAuthor struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Chirps int64  `json:"chirps"`
+}
+
+// dbPoolStats is the subset of sql.DBStats shown on the dashboard
+type dbPoolStats struct {
+	OpenConnections int           `json:"open_connections"`
+	InUse           int           `json:"in_use"`
+	Idle            int           `json:"idle"`
+	WaitCount       int64         `json:"wait_count"`
+	WaitDuration    time.Duration `json:"wait_duration_ns"`
+}
+
+// retryStats are the retry counts for one retry policy
+type retryStats struct {
+	Name     string `json:"name"`
+	Attempts int64  `json:"attempts"`
+	Retries  int64  `json:"retries"`
+	GiveUps  int64  `json:"give_ups"`
+}
+
+// routeStats are the request counts for one route and method
+type routeStats struct {
+	Route        string `json:"route"`
+	Method       string `json:"method"`
+	Requests     int64  `json:"requests"`
+	ClientErrors int64  `json:"client_errors"`
+	ServerErrors int64  `json:"server_errors"`
+}
+
+// cacheStats are the hit and miss counts for one cache. Entries is -1 for
+// caches kept in Redis, which aren't counted.
+type cacheStats struct {
+	Name    string `json:"name"`
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// adminStats holds everything shown on the admin dashboard. Hits counts
+// requests to the web client under /app. The user and chirp figures are
+// for the request's tenant; the rest cover the whole deployment.
+type adminStats struct {
+	Tenant       string        `json:"tenant"`
+	Hits         int64         `json:"hits"`
+	Days         int           `json:"days"`
+	TotalUsers   int64         `json:"total_users"`
+	TotalChirps  int64         `json:"total_chirps"`
+	ChirpsPerDay []periodCount `json:"chirps_per_day"`
+	TopAuthors   []topAuthor   `json:"top_authors"`
+	DBPool       dbPoolStats   `json:"db_pool"`
+	Retries      []retryStats  `json:"retries"`
+	Routes       []routeStats  `json:"routes"`
+	Caches       []cacheStats  `json:"caches"`
+}
+
+// buildAdminStats runs the aggregate queries behind the dashboard
+func (cfg *apiConfig) buildAdminStats(ctx context.Context) (adminStats, error) {
+	tenant := requestTenant(ctx)
+	stats := adminStats{
+		Tenant:       tenant.Slug,
+		Hits:         cfg.metrics.Requests("/app/"),
+		Days:         statsDays,
+		ChirpsPerDay: []periodCount{},
+		TopAuthors:   []topAuthor{},
+		Retries:      []retryStats{},
+		Routes:       []routeStats{},
+		Caches:       []cacheStats{},
+	}
+	since := time.Now().UTC().AddDate(0, 0, -statsDays)
+
+	var err error
+	if stats.TotalUsers, err = cfg.store.CountUsers(ctx, tenant.ID); err != nil {
+		return stats, err
+	}
+	if stats.TotalChirps, err = cfg.store.CountChirps(ctx, tenant.ID); err != nil {
+		return stats, err
+	}
+
+	// Chirps per day and top authors need Postgres
+	if cfg.db != nil {
+		daily, err := cfg.db.GetDailyChirpCounts(ctx, database.GetDailyChirpCountsParams{
+			TenantID:  tenant.ID,
+			CreatedAt: since,
+		})
+		if err != nil {
+			return stats, err
+		}
+		for _, row := range daily {
+			stats.ChirpsPerDay = append(stats.ChirpsPerDay, periodCount{Period: row.Day, Value: row.Chirps})
+		}
+
+		authors, err := cfg.db.GetTopAuthors(ctx, database.GetTopAuthorsParams{
+			TenantID:  tenant.ID,
+			CreatedAt: since,
+			Limit:     statsTopAuthors,
+		})
+		if err != nil {
+			return stats, err
+		}
+		for _, row := range authors {
+			stats.TopAuthors = append(stats.TopAuthors, topAuthor{
+				UserID: row.UserID.String(),
+				Email:  row.Email,
+				Chirps: row.Chirps,
+			})
+		}
+	}
+
+	if cfg.conn != nil {
+		pool := cfg.conn.Stats()
+		stats.DBPool = dbPoolStats{
+			OpenConnections: pool.OpenConnections,
+			InUse:           pool.InUse,
+			Idle:            pool.Idle,
+			WaitCount:       pool.WaitCount,
+			WaitDuration:    pool.WaitDuration,
+		}
+	}
+
+	for _, c := range retry.Stats() {
+		stats.Retries = append(stats.Retries, retryStats{
+			Name:     c.Name,
+			Attempts: c.Attempts,
+			Retries:  c.Retries,
+			GiveUps:  c.GiveUps,
+		})
+	}
+	for _, c := range cfg.metrics.Snapshot() {
+		stats.Routes = append(stats.Routes, routeStats{
+			Route:        c.Route,
+			Method:       c.Method,
+			Requests:     c.Requests,
+			ClientErrors: c.ClientErrors,
+			ServerErrors: c.ServerErrors,
+		})
+	}
+	if cfg.cache != nil {
+		for _, c := range cfg.cache.Stats() {
+			stats.Caches = append(stats.Caches, cacheStats{
+				Name:    c.Name,
+				Hits:    c.Hits,
+				Misses:  c.Misses,
+				Entries: c.Len,
+			})
+		}
+	}
+	return stats, nil
+}
+
+// statsHandler returns the admin dashboard stats as JSON
+func (cfg *apiConfig) statsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := cfg.buildAdminStats(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to build stats"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// prometheusHandler exposes the request, retry and cache counters for
+// Prometheus
+func (cfg *apiConfig) prometheusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var attempts, retries, giveUps []metrics.Counter
+	for _, c := range retry.Stats() {
+		labels := []string{"policy", c.Name}
+		attempts = append(attempts, metrics.Counter{Labels: labels, Value: c.Attempts})
+		retries = append(retries, metrics.Counter{Labels: labels, Value: c.Retries})
+		giveUps = append(giveUps, metrics.Counter{Labels: labels, Value: c.GiveUps})
+	}
+	var hits, misses []metrics.Counter
+	if cfg.cache != nil {
+		for _, c := range cfg.cache.Stats() {
+			labels := []string{"cache", c.Name}
+			hits = append(hits, metrics.Counter{Labels: labels, Value: c.Hits})
+			misses = append(misses, metrics.Counter{Labels: labels, Value: c.Misses})
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	cfg.metrics.WritePrometheus(w)
+	metrics.WriteCounter(w, "chirpy_retry_attempts_total", "Tries made, by retry policy.", attempts)
+	metrics.WriteCounter(w, "chirpy_retry_retries_total", "Failed tries that were retried, by retry policy.", retries)
+	metrics.WriteCounter(w, "chirpy_retry_give_ups_total", "Operations that failed for good, by retry policy.", giveUps)
+	metrics.WriteCounter(w, "chirpy_cache_hits_total", "Reads served from the in-process cache, by cache.", hits)
+	metrics.WriteCounter(w, "chirpy_cache_misses_total", "Reads that missed the in-process cache, by cache.", misses)
+}
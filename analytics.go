@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+)
+
+// Reporting windows for the analytics dashboard
+const (
+	analyticsDays  = 30
+	analyticsWeeks = 12
+)
+
+// periodCount is a single count for a day or week
+type periodCount struct {
+	Period time.Time `json:"period"`
+	Value  int64     `json:"value"`
+}
+
+// cohortRetention is the share of a signup cohort active in each following week
+type cohortRetention struct {
+	Week      time.Time `json:"week"`
+	Size      int64     `json:"size"`
+	Retention []float64 `json:"retention"`
+}
+
+// analyticsReport holds everything shown on the analytics dashboard
+type analyticsReport struct {
+	Days         int               `json:"days"`
+	Weeks        int               `json:"weeks"`
+	Signups      []periodCount     `json:"signups"`
+	DailyActive  []periodCount     `json:"daily_active"`
+	WeeklyActive []periodCount     `json:"weekly_active"`
+	Cohorts      []cohortRetention `json:"cohorts"`
+}
+
+// analyticsHandler renders signups, DAU/WAU and weekly cohort retention as HTML or CSV
+func (cfg *apiConfig) analyticsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := cfg.buildAnalyticsReport(r)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to build analytics"})
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="chirpy-analytics.csv"`)
+		w.WriteHeader(http.StatusOK)
+		writeAnalyticsCSV(w, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	templates.ExecuteTemplate(w, "analytics.html", report)
+}
+
+// buildAnalyticsReport runs the aggregate queries backing the dashboard
+func (cfg *apiConfig) buildAnalyticsReport(r *http.Request) (analyticsReport, error) {
+	ctx := r.Context()
+	now := time.Now().UTC()
+	dailySince := now.AddDate(0, 0, -analyticsDays)
+	weeklySince := now.AddDate(0, 0, -7*analyticsWeeks)
+
+	tenant := tenantID(ctx)
+
+	report := analyticsReport{Days: analyticsDays, Weeks: analyticsWeeks}
+
+	signups, err := cfg.db.GetDailySignups(ctx, database.GetDailySignupsParams{
+		TenantID:  tenant,
+		CreatedAt: dailySince,
+	})
+	if err != nil {
+		return report, err
+	}
+	for _, row := range signups {
+		report.Signups = append(report.Signups, periodCount{Period: row.Day, Value: row.Signups})
+	}
+
+	dau, err := cfg.db.GetDailyActiveUsers(ctx, database.GetDailyActiveUsersParams{
+		TenantID:  tenant,
+		CreatedAt: dailySince,
+	})
+	if err != nil {
+		return report, err
+	}
+	for _, row := range dau {
+		report.DailyActive = append(report.DailyActive, periodCount{Period: row.Day, Value: row.ActiveUsers})
+	}
+
+	wau, err := cfg.db.GetWeeklyActiveUsers(ctx, database.GetWeeklyActiveUsersParams{
+		TenantID:  tenant,
+		CreatedAt: weeklySince,
+	})
+	if err != nil {
+		return report, err
+	}
+	for _, row := range wau {
+		report.WeeklyActive = append(report.WeeklyActive, periodCount{Period: row.Week, Value: row.ActiveUsers})
+	}
+
+	sizes, err := cfg.db.GetWeeklyCohortSizes(ctx, database.GetWeeklyCohortSizesParams{
+		TenantID:  tenant,
+		CreatedAt: weeklySince,
+	})
+	if err != nil {
+		return report, err
+	}
+	retention, err := cfg.db.GetWeeklyCohortRetention(ctx, database.GetWeeklyCohortRetentionParams{
+		TenantID:  tenant,
+		CreatedAt: weeklySince,
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// Build one retention row per cohort, with a slot for every week since signup
+	index := make(map[time.Time]int, len(sizes))
+	for _, row := range sizes {
+		weeks := int(now.Sub(row.CohortWeek).Hours()/(24*7)) + 1
+		index[row.CohortWeek] = len(report.Cohorts)
+		report.Cohorts = append(report.Cohorts, cohortRetention{
+			Week:      row.CohortWeek,
+			Size:      row.Users,
+			Retention: make([]float64, weeks),
+		})
+	}
+	for _, row := range retention {
+		i, ok := index[row.CohortWeek]
+		if !ok {
+			continue
+		}
+		cohort := report.Cohorts[i]
+		if int(row.WeekNumber) >= len(cohort.Retention) || cohort.Size == 0 {
+			continue
+		}
+		cohort.Retention[row.WeekNumber] = 100 * float64(row.ActiveUsers) / float64(cohort.Size)
+	}
+
+	return report, nil
+}
+
+// writeAnalyticsCSV writes the report as a single long-format CSV table
+func writeAnalyticsCSV(w http.ResponseWriter, report analyticsReport) {
+	out := csv.NewWriter(w)
+	out.Write([]string{"metric", "period", "week_number", "value"})
+	for _, row := range report.Signups {
+		out.Write([]string{"signups", row.Period.Format("2006-01-02"), "", strconv.FormatInt(row.Value, 10)})
+	}
+	for _, row := range report.DailyActive {
+		out.Write([]string{"dau", row.Period.Format("2006-01-02"), "", strconv.FormatInt(row.Value, 10)})
+	}
+	for _, row := range report.WeeklyActive {
+		out.Write([]string{"wau", row.Period.Format("2006-01-02"), "", strconv.FormatInt(row.Value, 10)})
+	}
+	for _, cohort := range report.Cohorts {
+		out.Write([]string{"cohort_size", cohort.Week.Format("2006-01-02"), "", strconv.FormatInt(cohort.Size, 10)})
+		for week, pct := range cohort.Retention {
+			out.Write([]string{"retention_pct", cohort.Week.Format("2006-01-02"), strconv.Itoa(week), fmt.Sprintf("%.2f", pct)})
+		}
+	}
+	out.Flush()
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// Audited actions
+const (
+	auditActionReset = "reset"
+)
+
+// Audit log page sizes
+const (
+	auditDefaultLimit = 50
+	auditMaxLimit     = 200
+)
+
+// auditEntryResponse represents an audit log entry
+type auditEntryResponse struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	RequestID string    `json:"request_id"`
+	Details   string    `json:"details"`
+}
+
+// auditLogResponse represents a page of the audit log
+type auditLogResponse struct {
+	Entries    []auditEntryResponse `json:"entries"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// requestActor identifies who made a request: "admin:<name>" for a request
+// that carried an admin token, or else the client address
+func requestActor(r *http.Request) string {
+	if name := requestAdmin(r.Context()); name != "" {
+		return "admin:" + name
+	}
+	return clientIP(r)
+}
+
+// clientIP returns the address the request came from, without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// recordAudit writes an audit log entry for an admin or destructive action.
+// Failures are logged rather than failing the action itself. Nothing is
+// recorded in demo mode.
+func (cfg *apiConfig) recordAudit(r *http.Request, action, target, details string) {
+	if cfg.db == nil {
+		return
+	}
+	err := cfg.db.CreateAuditLogEntry(r.Context(), database.CreateAuditLogEntryParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		Actor:     requestActor(r),
+		Action:    action,
+		Target:    target,
+		RequestID: requestIDFrom(r.Context()),
+		Details:   details,
+		TenantID:  tenantID(r.Context()),
+	})
+	if err != nil {
+		log.Printf("failed to record audit entry %q on %q: %v", action, target, err)
+	}
+}
+
+// pageCursor is the position of the last item on a page of a list ordered
+// by time and ID, such as the audit log
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// decodePageCursor verifies and parses a cursor from next_cursor
+func (cfg *apiConfig) decodePageCursor(token string) (time.Time, uuid.UUID, error) {
+	var c pageCursor
+	if err := cfg.cursors.Decode(token, &c); err != nil {
+		return time.Time{}, uuid.Nil, &request.FieldError{Field: "cursor", Message: "is invalid"}
+	}
+	return c.CreatedAt, c.ID, nil
+}
+
+// auditLogHandler lists audit log entries, newest first, filtered by
+// action, actor, target and since, and paginated with cursor and limit
+func (cfg *apiConfig) auditLogHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	params := database.ListAuditLogParams{
+		TenantID:        tenantID(r.Context()),
+		Action:          query.Get("action"),
+		Actor:           query.Get("actor"),
+		Target:          query.Get("target"),
+		BeforeCreatedAt: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC),
+		BeforeID:        uuid.Max,
+	}
+
+	limit, err := request.ParseInt(r, "limit", auditDefaultLimit, 1, auditMaxLimit)
+	if err == nil {
+		params.Since, err = request.ParseTime(r, "since")
+	}
+	if err == nil && query.Get("cursor") != "" {
+		params.BeforeCreatedAt, params.BeforeID, err = cfg.decodePageCursor(query.Get("cursor"))
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+	params.RowLimit = int32(limit)
+
+	entries, err := cfg.db.ListAuditLog(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list audit log"})
+		return
+	}
+
+	resp := auditLogResponse{Entries: []auditEntryResponse{}}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, auditEntryToResponse(entry))
+	}
+	if len(entries) == limit {
+		last := entries[len(entries)-1]
+		resp.NextCursor, err = cfg.cursors.Encode(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list audit log"})
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// auditExportHandler streams every audit log entry matching the filters of
+// auditLogHandler as newline-delimited JSON, newest first. Each page is
+// read with its own timeout, so a long export never holds a query open for
+// longer than a normal request would.
+func (cfg *apiConfig) auditExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	params := database.ListAuditLogParams{
+		TenantID:        tenantID(r.Context()),
+		Action:          query.Get("action"),
+		Actor:           query.Get("actor"),
+		Target:          query.Get("target"),
+		BeforeCreatedAt: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC),
+		BeforeID:        uuid.Max,
+		RowLimit:        auditMaxLimit,
+	}
+	var err error
+	if params.Since, err = request.ParseTime(r, "since"); err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	for started := false; ; started = true {
+		entries, err := cfg.listAuditPage(r.Context(), params)
+		if err != nil && !started {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list audit log"})
+			return
+		}
+		if err != nil {
+			// The status is already sent, so the export just ends early
+			log.Printf("audit log export stopped: %v", err)
+			return
+		}
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", `attachment; filename="chirpy-audit.ndjson"`)
+			w.WriteHeader(http.StatusOK)
+		}
+
+		for _, entry := range entries {
+			enc.Encode(auditEntryToResponse(entry))
+		}
+		http.NewResponseController(w).Flush()
+		if len(entries) < int(params.RowLimit) {
+			return
+		}
+		last := entries[len(entries)-1]
+		params.BeforeCreatedAt, params.BeforeID = last.CreatedAt, last.ID
+	}
+}
+
+// listAuditPage reads one page of the audit log within the request timeout
+func (cfg *apiConfig) listAuditPage(ctx context.Context, params database.ListAuditLogParams) ([]database.AuditLog, error) {
+	if cfg.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.requestTimeout)
+		defer cancel()
+	}
+	return cfg.db.ListAuditLog(ctx, params)
+}
+
+// auditEntryToResponse converts an audit log row to its JSON form
+func auditEntryToResponse(entry database.AuditLog) auditEntryResponse {
+	return auditEntryResponse{
+		ID:        entry.ID.String(),
+		CreatedAt: entry.CreatedAt,
+		Actor:     entry.Actor,
+		Action:    entry.Action,
+		Target:    entry.Target,
+		RequestID: entry.RequestID,
+		Details:   entry.Details,
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/cache"
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisConnectTimeout bounds the check that Redis answers at startup
+const redisConnectTimeout = 5 * time.Second
+
+// newCachedStore serves chirps and rechirp counts from caches in front of
+// st. They are kept in Redis when REDIS_URL is set and Redis answers, so
+// instances share them, and in process otherwise.
+func newCachedStore(st store.Store, c config.Cache) *store.Cached {
+	if c.RedisURL != "" {
+		client, err := connectRedis(c.RedisURL)
+		if err == nil {
+			return store.NewCached(st,
+				cache.NewRedis[uuid.UUID, database.Chirp](client, "chirps", c.ChirpTTL),
+				cache.NewRedis[uuid.UUID, int64](client, "rechirp_counts", c.CountTTL))
+		}
+		log.Printf("failed to connect to Redis, caching in process instead: %v", err)
+	}
+	return store.NewCached(st,
+		cache.New[uuid.UUID, database.Chirp]("chirps", c.Size, c.ChirpTTL),
+		cache.New[uuid.UUID, int64]("rechirp_counts", c.Size, c.CountTTL))
+}
+
+// connectRedis opens a client for the Redis at rawURL and checks that it
+// answers
+func connectRedis(rawURL string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestCachedChirpReads(t *testing.T) {
+	cfg := testConfig()
+	cfg.Cache.Size = 100
+	cfg.Cache.ChirpTTL = time.Minute
+	cfg.Cache.CountTTL = time.Minute
+	srv := newTestServer(t, cfg)
+
+	author := srv.createUser("author@example.com")
+	fan := srv.createUser("fan@example.com")
+	chirp := srv.createChirp(author.ID, "hello")
+
+	// The chirp was cached on create, and its count on the first read
+	srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, "")
+	srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, "")
+
+	// A rechirp drops the cached count, so the next read sees it
+	rec := srv.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/rechirp", `{"user_id":"`+fan.ID+`"}`)
+	if rec.Code >= 300 {
+		t.Fatalf("rechirp status = %d (%s)", rec.Code, rec.Body)
+	}
+	got := decode[chirpResponse](t, srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, ""))
+	if got.RechirpCount != 1 {
+		t.Errorf("rechirp count = %d, want 1", got.RechirpCount)
+	}
+
+	caches := map[string]cacheStats{}
+	for _, c := range decode[adminStats](t, srv.do(http.MethodGet, "/admin/stats", "")).Caches {
+		caches[c.Name] = c
+	}
+	if got := caches["chirps"]; got.Hits < 3 || got.Misses != 0 {
+		t.Errorf("chirps cache = %+v, want 3 or more hits and no misses", got)
+	}
+	if got := caches["rechirp_counts"]; got.Hits == 0 || got.Misses < 2 {
+		t.Errorf("rechirp_counts cache = %+v, want hits and 2 or more misses", got)
+	}
+
+	rec = srv.do(http.MethodGet, "/metrics", "")
+	want := `chirpy_cache_misses_total{cache="chirps"} 0`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("/metrics is missing %q:\n%s", want, rec.Body)
+	}
+}
+
+func TestCacheSharedThroughRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cfg := testConfig()
+	cfg.Cache.Size = 100
+	cfg.Cache.ChirpTTL = time.Minute
+	cfg.Cache.CountTTL = time.Minute
+	cfg.Cache.RedisURL = "redis://" + mr.Addr()
+
+	// Two instances in front of the same database
+	st := store.NewMemory()
+	first := &testServer{t: t, handler: NewServer(cfg, st), store: st}
+	second := &testServer{t: t, handler: NewServer(cfg, st), store: st}
+
+	author := first.createUser("author@example.com")
+	fan := first.createUser("fan@example.com")
+	chirp := first.createChirp(author.ID, "hello")
+	second.do(http.MethodGet, "/api/chirps/"+chirp.ID, "")
+
+	// A rechirp on one instance drops the count the other one cached
+	rec := first.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/rechirp", `{"user_id":"`+fan.ID+`"}`)
+	if rec.Code >= 300 {
+		t.Fatalf("rechirp status = %d (%s)", rec.Code, rec.Body)
+	}
+	got := decode[chirpResponse](t, second.do(http.MethodGet, "/api/chirps/"+chirp.ID, ""))
+	if got.RechirpCount != 1 {
+		t.Errorf("rechirp count on the other instance = %d, want 1", got.RechirpCount)
+	}
+
+	for _, c := range decode[adminStats](t, second.do(http.MethodGet, "/admin/stats", "")).Caches {
+		if c.Name == "chirps" && (c.Hits == 0 || c.Entries != -1) {
+			t.Errorf("chirps cache = %+v, want hits from Redis", c)
+		}
+	}
+}
+
+func TestCacheFallsBackWithoutRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	mr.Close()
+
+	cfg := testConfig()
+	cfg.Cache.Size = 100
+	cfg.Cache.ChirpTTL = time.Minute
+	cfg.Cache.CountTTL = time.Minute
+	cfg.Cache.RedisURL = "redis://" + addr
+	srv := newTestServer(t, cfg)
+
+	chirp := srv.createChirp(srv.createUser("a@example.com").ID, "hello")
+	srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, "")
+	for _, c := range decode[adminStats](t, srv.do(http.MethodGet, "/admin/stats", "")).Caches {
+		if c.Name == "chirps" && (c.Hits == 0 || c.Entries != 1) {
+			t.Errorf("chirps cache = %+v, want an in-process cache with the chirp", c)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// maxBatchChirps is how many chirps one batch lookup may ask for
+const maxBatchChirps = 100
+
+// chirpBatchRequest represents the incoming JSON payload
+type chirpBatchRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// chirpBatchResponse holds the requested chirps in request order, with nil
+// for IDs that don't exist
+type chirpBatchResponse struct {
+	Chirps []*chirpResponse `json:"chirps"`
+}
+
+// chirpBatchHandler looks up many chirps at once, for clients rendering
+// timelines from cached IDs
+func (cfg *apiConfig) chirpBatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Read and parse request body
+	var req chirpBatchRequest
+	err := request.DecodeJSON(r, &req, request.DefaultMaxBodyBytes)
+	if err == nil && (len(req.IDs) == 0 || len(req.IDs) > maxBatchChirps) {
+		err = &request.FieldError{Field: "ids", Message: fmt.Sprintf("must have between 1 and %d IDs", maxBatchChirps)}
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Load the chirps, their rechirp and view counts, link previews and polls
+	// in one query each
+	chirps, err := cfg.store.GetChirpsByIDs(r.Context(), database.GetChirpsByIDsParams{
+		TenantID: tenantID(r.Context()),
+		Ids:      req.IDs,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirps"})
+		return
+	}
+	counts, err := cfg.store.CountRechirpsByChirpIDs(r.Context(), req.IDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to count rechirps"})
+		return
+	}
+	views, err := cfg.viewCounts(r.Context(), req.IDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to count views"})
+		return
+	}
+	var urls []string
+	for _, chirp := range chirps {
+		urls = append(urls, chirpURLs(chirp.Body)...)
+	}
+	previews := cfg.loadLinkPreviews(r.Context(), urls)
+	polls, err := cfg.loadPolls(r.Context(), chirps)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get polls"})
+		return
+	}
+	held, err := cfg.heldChirpIDs(r.Context(), req.IDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirps"})
+		return
+	}
+
+	byID := make(map[uuid.UUID]database.Chirp, len(chirps))
+	for _, chirp := range chirps {
+		byID[chirp.ID] = chirp
+	}
+	rechirps := make(map[uuid.UUID]int64, len(counts))
+	for _, row := range counts {
+		rechirps[row.ChirpID] = row.Count
+	}
+
+	resp := chirpBatchResponse{Chirps: make([]*chirpResponse, len(req.IDs))}
+	for i, id := range req.IDs {
+		// Held chirps look missing, as they do to GET /api/chirps/{chirpID}
+		chirp, ok := byID[id]
+		if !ok || held[id] {
+			continue
+		}
+		resp.Chirps[i] = &chirpResponse{
+			ID:           chirp.ID.String(),
+			CreatedAt:    chirp.CreatedAt,
+			UpdatedAt:    chirp.UpdatedAt,
+			Body:         chirp.Body,
+			UserID:       chirp.UserID.String(),
+			RechirpCount: rechirps[chirp.ID],
+			ViewCount:    views[chirp.ID],
+			LinkPreviews: previewsFor(chirp.Body, previews),
+			Poll:         polls[chirp.ID],
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
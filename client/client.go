@@ -0,0 +1,533 @@
+// Package client is a typed Go client for the Chirpy API.
+//
+//	c := client.New("https://chirpy.example.com")
+//	user, err := c.CreateUser(ctx, "me@example.com")
+//
+// Failed requests are retried with backoff when it is safe to do so:
+// network errors and 5xx and 429 responses are retried, other errors are
+// returned as *Error. Chirp and user creation send an Idempotency-Key so a
+// retry never creates a duplicate.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/retry"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPolicy retries a request up to three times
+var DefaultPolicy = retry.Policy{
+	Name:        "client",
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+	Multiplier:  2,
+	Jitter:      0.2,
+}
+
+// Client calls a Chirpy server
+type Client struct {
+	baseURL       string
+	httpClient    *http.Client
+	policy        retry.Policy
+	webhookSecret string
+	tenant        string
+	adminToken    string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithMaxAttempts sets how many times a request is tried, including the
+// first; 1 disables retries
+func WithMaxAttempts(n int) Option {
+	return func(c *Client) { c.policy.MaxAttempts = n }
+}
+
+// WithWebhookSecret sets the EMAIL_WEBHOOK_SECRET sent as a bearer token by
+// SendInboundEmail
+func WithWebhookSecret(secret string) Option {
+	return func(c *Client) { c.webhookSecret = secret }
+}
+
+// WithTenant sends every request to the tenant with this slug instead of
+// the one serving the server's host
+func WithTenant(slug string) Option {
+	return func(c *Client) { c.tenant = slug }
+}
+
+// WithAdminToken sets one of the server's ADMIN_TOKENS, sent as a bearer
+// token to the admin endpoints
+func WithAdminToken(token string) Option {
+	return func(c *Client) { c.adminToken = token }
+}
+
+// New returns a Client for the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		policy:     DefaultPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// call describes one API request
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	header http.Header
+	// out receives the decoded JSON response, or the raw body if it is a
+	// *[]byte; nil discards the response
+	out any
+}
+
+// do sends a request, retrying transient failures
+func (c *Client) do(ctx context.Context, cl call) error {
+	var body []byte
+	if cl.body != nil {
+		var err error
+		if body, err = json.Marshal(cl.body); err != nil {
+			return err
+		}
+	}
+	target := c.baseURL + cl.path
+	if len(cl.query) > 0 {
+		target += "?" + cl.query.Encode()
+	}
+
+	return c.policy.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, cl.method, target, bytes.NewReader(body))
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		for k, v := range cl.header {
+			req.Header[k] = v
+		}
+		if c.tenant != "" {
+			req.Header.Set("X-Chirpy-Tenant", c.tenant)
+		}
+		if c.adminToken != "" && strings.HasPrefix(cl.path, "/admin/") {
+			req.Header.Set("Authorization", "Bearer "+c.adminToken)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return retry.Permanent(err)
+			}
+			return err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode >= 400 {
+			apiErr := &Error{StatusCode: resp.StatusCode}
+			json.Unmarshal(data, apiErr)
+			if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+				return apiErr
+			}
+			return retry.Permanent(apiErr)
+		}
+
+		switch out := cl.out.(type) {
+		case nil:
+			return nil
+		case *[]byte:
+			*out = data
+			return nil
+		default:
+			if err := json.Unmarshal(data, out); err != nil {
+				return retry.Permanent(fmt.Errorf("chirpy: decoding response: %w", err))
+			}
+			return nil
+		}
+	})
+}
+
+// idempotent returns headers that make a POST safe to retry
+func idempotent() http.Header {
+	return http.Header{"Idempotency-Key": {uuid.NewString()}}
+}
+
+// Healthz checks that the server is up
+func (c *Client) Healthz(ctx context.Context) error {
+	return c.do(ctx, call{method: http.MethodGet, path: "/api/healthz"})
+}
+
+// ValidateChirp checks a chirp body's length and returns it cleaned
+func (c *Client) ValidateChirp(ctx context.Context, body string) (ValidatedChirp, error) {
+	var out ValidatedChirp
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/validate_chirp",
+		body:   map[string]string{"body": body},
+		out:    &out,
+	})
+	return out, err
+}
+
+// CreateUser creates a user
+func (c *Client) CreateUser(ctx context.Context, email string) (User, error) {
+	var out User
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/users",
+		body:   map[string]string{"email": email},
+		header: idempotent(),
+		out:    &out,
+	})
+	return out, err
+}
+
+// CreateChirp posts a chirp as userID
+func (c *Client) CreateChirp(ctx context.Context, userID uuid.UUID, body string) (Chirp, error) {
+	var out Chirp
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/chirps",
+		body:   map[string]any{"body": body, "user_id": userID},
+		header: idempotent(),
+		out:    &out,
+	})
+	return out, err
+}
+
+// CreateChirpWithPoll posts a chirp with a poll attached
+func (c *Client) CreateChirpWithPoll(ctx context.Context, userID uuid.UUID, body string, poll NewPoll) (Chirp, error) {
+	var out Chirp
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/chirps",
+		body: map[string]any{"body": body, "user_id": userID, "poll": map[string]any{
+			"options":          poll.Options,
+			"duration_minutes": int(poll.Duration.Minutes()),
+		}},
+		header: idempotent(),
+		out:    &out,
+	})
+	return out, err
+}
+
+// Vote votes for the option at index option in a chirp's poll and returns
+// the poll with MyVote set. The server counts votes per client IP and
+// ignores userID, which is still sent for older servers.
+func (c *Client) Vote(ctx context.Context, chirpID, userID uuid.UUID, option int) (Poll, error) {
+	var out Poll
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/chirps/" + chirpID.String() + "/poll/vote",
+		body:   map[string]any{"user_id": userID, "option": option},
+		out:    &out,
+	})
+	return out, err
+}
+
+// GetChirp returns a chirp
+func (c *Client) GetChirp(ctx context.Context, chirpID uuid.UUID) (Chirp, error) {
+	var out Chirp
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/api/chirps/" + chirpID.String(),
+		out:    &out,
+	})
+	return out, err
+}
+
+// GetChirpPermalink returns the v1 permalink representation of a chirp
+func (c *Client) GetChirpPermalink(ctx context.Context, chirpID uuid.UUID) (ChirpV1, error) {
+	var out ChirpV1
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/api/chirps/" + chirpID.String() + "?schema=v1",
+		out:    &out,
+	})
+	return out, err
+}
+
+// GetChirps looks up to 100 chirps at once. The result is in the order of
+// ids, with nil for chirps that don't exist.
+func (c *Client) GetChirps(ctx context.Context, ids []uuid.UUID) ([]*Chirp, error) {
+	var out struct {
+		Chirps []*Chirp `json:"chirps"`
+	}
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/chirps/batch",
+		body:   map[string]any{"ids": ids},
+		out:    &out,
+	})
+	return out.Chirps, err
+}
+
+// Rechirp rechirps a chirp as userID and returns the chirp with its new
+// rechirp count
+func (c *Client) Rechirp(ctx context.Context, chirpID, userID uuid.UUID) (Chirp, error) {
+	var out Chirp
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/chirps/" + chirpID.String() + "/rechirp",
+		body:   map[string]any{"user_id": userID},
+		out:    &out,
+	})
+	return out, err
+}
+
+// Unrechirp undoes userID's rechirp of a chirp
+func (c *Client) Unrechirp(ctx context.Context, chirpID, userID uuid.UUID) error {
+	return c.do(ctx, call{
+		method: http.MethodDelete,
+		path:   "/api/chirps/" + chirpID.String() + "/rechirp",
+		body:   map[string]any{"user_id": userID},
+	})
+}
+
+// RecordView records a view of a chirp. The server counts views per
+// client IP and ignores userID, which is still sent for older servers.
+func (c *Client) RecordView(ctx context.Context, chirpID, userID uuid.UUID) error {
+	cl := call{method: http.MethodPost, path: "/api/chirps/" + chirpID.String() + "/view"}
+	if userID != uuid.Nil {
+		cl.body = map[string]any{"user_id": userID}
+	}
+	return c.do(ctx, cl)
+}
+
+// AuthorViews returns daily views of a user's chirps over the last days days
+// (0 for the server default)
+func (c *Client) AuthorViews(ctx context.Context, userID uuid.UUID, days int) (AuthorViews, error) {
+	query := url.Values{}
+	if days > 0 {
+		query.Set("days", strconv.Itoa(days))
+	}
+	var out AuthorViews
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/api/users/" + userID.String() + "/analytics/views",
+		query:  query,
+		out:    &out,
+	})
+	return out, err
+}
+
+// SendInboundEmail delivers an email to the posting-by-email webhook,
+// authenticating with the secret from WithWebhookSecret
+func (c *Client) SendInboundEmail(ctx context.Context, email InboundEmail) (Chirp, error) {
+	var out Chirp
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/email/inbound",
+		body:   email,
+		header: http.Header{"Authorization": {"Bearer " + c.webhookSecret}},
+		out:    &out,
+	})
+	return out, err
+}
+
+// Stats returns the admin dashboard data
+func (c *Client) Stats(ctx context.Context) (Stats, error) {
+	var out Stats
+	err := c.do(ctx, call{method: http.MethodGet, path: "/admin/stats", out: &out})
+	return out, err
+}
+
+// Reset deletes all users (dev and demo only) and, in dev, clears the
+// request and retry metrics
+func (c *Client) Reset(ctx context.Context) error {
+	return c.do(ctx, call{method: http.MethodPost, path: "/admin/reset"})
+}
+
+// AnalyticsCSV returns the analytics report as CSV
+func (c *Client) AnalyticsCSV(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/admin/analytics",
+		query:  url.Values{"format": {"csv"}},
+		out:    &out,
+	})
+	return out, err
+}
+
+// Jobs returns the background job queue status
+func (c *Client) Jobs(ctx context.Context) (JobsStatus, error) {
+	var out JobsStatus
+	err := c.do(ctx, call{method: http.MethodGet, path: "/admin/jobs", out: &out})
+	return out, err
+}
+
+// ReportChirp reports a chirp as userID for reason, such as "spam"
+func (c *Client) ReportChirp(ctx context.Context, chirpID, userID uuid.UUID, reason, details string) (Report, error) {
+	var out Report
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/chirps/" + chirpID.String() + "/report",
+		body:   map[string]any{"user_id": userID, "reason": reason, "details": details},
+		out:    &out,
+	})
+	return out, err
+}
+
+// InstanceRules returns the instance rules and the reasons a chirp can be
+// reported for
+func (c *Client) InstanceRules(ctx context.Context) (InstanceRules, error) {
+	var out InstanceRules
+	err := c.do(ctx, call{method: http.MethodGet, path: "/api/instance/rules", out: &out})
+	return out, err
+}
+
+// UpdateInstanceRules replaces the instance rules and report reasons
+func (c *Client) UpdateInstanceRules(ctx context.Context, rules InstanceRules) (InstanceRules, error) {
+	var out InstanceRules
+	err := c.do(ctx, call{method: http.MethodPut, path: "/admin/rules", body: rules, out: &out})
+	return out, err
+}
+
+// ReportsPage returns one page of reports with status ("" for open), oldest
+// first. Pass the previous page's NextCursor as cursor, or "" for the first
+// page.
+func (c *Client) ReportsPage(ctx context.Context, status, cursor string) (ReportsPage, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	var out ReportsPage
+	err := c.do(ctx, call{method: http.MethodGet, path: "/admin/reports", query: query, out: &out})
+	return out, err
+}
+
+// ResolveReport upholds a report, closing every open report on its chirp
+func (c *Client) ResolveReport(ctx context.Context, reportID uuid.UUID) (Report, error) {
+	var out Report
+	err := c.do(ctx, call{method: http.MethodPost, path: "/admin/reports/" + reportID.String() + "/resolve", out: &out})
+	return out, err
+}
+
+// DismissReport dismisses a report, closing every open report on its chirp
+func (c *Client) DismissReport(ctx context.Context, reportID uuid.UUID) (Report, error) {
+	var out Report
+	err := c.do(ctx, call{method: http.MethodPost, path: "/admin/reports/" + reportID.String() + "/dismiss", out: &out})
+	return out, err
+}
+
+// PendingChirpsPage returns one page of chirps held by the classifier,
+// oldest first. Pass the previous page's NextCursor as cursor, or "" for
+// the first page.
+func (c *Client) PendingChirpsPage(ctx context.Context, cursor string) (PendingChirpsPage, error) {
+	query := url.Values{}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	var out PendingChirpsPage
+	err := c.do(ctx, call{method: http.MethodGet, path: "/admin/moderation", query: query, out: &out})
+	return out, err
+}
+
+// ApproveChirp publishes a chirp held by the classifier
+func (c *Client) ApproveChirp(ctx context.Context, chirpID uuid.UUID) error {
+	return c.do(ctx, call{method: http.MethodPost, path: "/admin/moderation/" + chirpID.String() + "/approve"})
+}
+
+// RejectChirp keeps a chirp held by the classifier hidden and gives its
+// author a strike
+func (c *Client) RejectChirp(ctx context.Context, chirpID uuid.UUID) error {
+	return c.do(ctx, call{method: http.MethodPost, path: "/admin/moderation/" + chirpID.String() + "/reject"})
+}
+
+// AuditLogPage returns one page of the audit log, newest first. Pass the
+// previous page's NextCursor as cursor, or "" for the first page.
+func (c *Client) AuditLogPage(ctx context.Context, filter AuditFilter, cursor string) (AuditPage, error) {
+	query := url.Values{}
+	if filter.Action != "" {
+		query.Set("action", filter.Action)
+	}
+	if filter.Actor != "" {
+		query.Set("actor", filter.Actor)
+	}
+	if filter.Target != "" {
+		query.Set("target", filter.Target)
+	}
+	if !filter.Since.IsZero() {
+		query.Set("since", filter.Since.Format(time.RFC3339Nano))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	var out AuditPage
+	err := c.do(ctx, call{method: http.MethodGet, path: "/admin/audit", query: query, out: &out})
+	return out, err
+}
+
+// AuditLog iterates over every matching audit log entry, newest first,
+// fetching pages as needed. Iteration stops after the first error.
+//
+//	for entry, err := range c.AuditLog(ctx, client.AuditFilter{Action: "reset"}) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (c *Client) AuditLog(ctx context.Context, filter AuditFilter) iter.Seq2[AuditEntry, error] {
+	return func(yield func(AuditEntry, error) bool) {
+		cursor := ""
+		for {
+			page, err := c.AuditLogPage(ctx, filter, cursor)
+			if err != nil {
+				yield(AuditEntry{}, err)
+				return
+			}
+			for _, entry := range page.Entries {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
+
+// IsNotFound reports whether err is a 404 from the server
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateChirp(t *testing.T) {
+	userID := uuid.New()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/chirps" {
+			t.Errorf("got %s %s, want POST /api/chirps", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Idempotency-Key") == "" {
+			t.Error("missing Idempotency-Key")
+		}
+		var req struct {
+			Body   string    `json:"body"`
+			UserID uuid.UUID `json:"user_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.Body != "hello" || req.UserID != userID {
+			t.Errorf("request = %+v", req)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"id": uuid.New(), "body": req.Body, "user_id": req.UserID})
+	}))
+	defer srv.Close()
+
+	chirp, err := New(srv.URL).CreateChirp(context.Background(), userID, "hello")
+	if err != nil {
+		t.Fatalf("CreateChirp: %v", err)
+	}
+	if chirp.Body != "hello" || chirp.UserID != userID {
+		t.Errorf("CreateChirp = %+v", chirp)
+	}
+}
+
+func TestRetriesServerErrorsWithSameIdempotencyKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"id": uuid.New(), "email": "a@example.com"})
+	}))
+	defer srv.Close()
+
+	user, err := New(srv.URL).CreateUser(context.Background(), "a@example.com")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if user.Email != "a@example.com" {
+		t.Errorf("Email = %q", user.Email)
+	}
+	if len(keys) != 2 || keys[0] != keys[1] {
+		t.Errorf("Idempotency-Keys = %q, want the same key twice", keys)
+	}
+}
+
+func TestClientErrorsAreNotRetried(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "must be a valid UUID", "field": "chirpID"})
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).GetChirp(context.Background(), uuid.New())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetChirp error = %v, want *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Field != "chirpID" {
+		t.Errorf("error = %+v", apiErr)
+	}
+	if calls != 1 {
+		t.Errorf("server called %d times, want 1", calls)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Chirp not found"})
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).GetChirp(context.Background(), uuid.New())
+	if !IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) = false", err)
+	}
+}
+
+func TestSendInboundEmailAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer s3cret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"id": uuid.New(), "body": "hi"})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithWebhookSecret("s3cret"))
+	if _, err := c.SendInboundEmail(context.Background(), InboundEmail{Text: "hi"}); err != nil {
+		t.Fatalf("SendInboundEmail: %v", err)
+	}
+}
+
+func TestWithTenant(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Chirpy-Tenant"); got != "club" {
+			t.Errorf("X-Chirpy-Tenant = %q", got)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"tenant": "club"})
+	}))
+	defer srv.Close()
+
+	stats, err := New(srv.URL, WithTenant("club")).Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Tenant != "club" {
+		t.Errorf("Tenant = %q, want %q", stats.Tenant, "club")
+	}
+}
+
+func TestWithAdminToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := ""
+		if strings.HasPrefix(r.URL.Path, "/admin/") {
+			want = "Bearer admin-token"
+		}
+		if got := r.Header.Get("Authorization"); got != want {
+			t.Errorf("%s: Authorization = %q, want %q", r.URL.Path, got, want)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithAdminToken("admin-token"))
+	if _, err := c.Stats(context.Background()); err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if _, err := c.GetChirp(context.Background(), uuid.New()); err != nil {
+		t.Fatalf("GetChirp: %v", err)
+	}
+}
+
+func TestAuditLogIteratesPages(t *testing.T) {
+	pages := map[string]AuditPage{
+		"":   {Entries: []AuditEntry{{Action: "reset", Target: "1"}, {Action: "reset", Target: "2"}}, NextCursor: "c1"},
+		"c1": {Entries: []AuditEntry{{Action: "reset", Target: "3"}}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("action"); got != "reset" {
+			t.Errorf("action = %q", got)
+		}
+		page, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			t.Fatalf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	var targets []string
+	for entry, err := range New(srv.URL).AuditLog(context.Background(), AuditFilter{Action: "reset"}) {
+		if err != nil {
+			t.Fatalf("AuditLog: %v", err)
+		}
+		targets = append(targets, entry.Target)
+	}
+	if len(targets) != 3 || targets[0] != "1" || targets[2] != "3" {
+		t.Errorf("targets = %q, want [1 2 3]", targets)
+	}
+}
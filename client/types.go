@@ -0,0 +1,298 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User is a Chirpy user
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Email     string    `json:"email"`
+	// PostingAddress is only returned when the user is created, and only
+	// when posting by email is enabled
+	PostingAddress string `json:"posting_address,omitempty"`
+}
+
+// LinkPreview is the Open Graph metadata fetched for a link in a chirp
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+}
+
+// Chirp is a post
+type Chirp struct {
+	ID           uuid.UUID     `json:"id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	Body         string        `json:"body"`
+	UserID       uuid.UUID     `json:"user_id"`
+	RechirpCount int64         `json:"rechirp_count"`
+	ViewCount    int64         `json:"view_count"`
+	LinkPreviews []LinkPreview `json:"link_previews"`
+	Poll         *Poll         `json:"poll,omitempty"`
+	// Pending is set on a new chirp the server's classifier flagged. It is
+	// hidden until a moderator approves it.
+	Pending bool `json:"pending,omitempty"`
+}
+
+// Poll is a poll attached to a chirp. Tallies is nil while the poll is
+// open, and MyVote is only set on the poll Vote returns.
+type Poll struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+	Closed   bool      `json:"closed"`
+	Tallies  []int64   `json:"tallies,omitempty"`
+	MyVote   *int      `json:"my_vote,omitempty"`
+}
+
+// NewPoll is a poll to attach to a new chirp. A zero Duration uses the
+// server default of 24 hours.
+type NewPoll struct {
+	Options  []string
+	Duration time.Duration
+}
+
+// ChirpV1 is the frozen v1 permalink representation of a chirp
+type ChirpV1 struct {
+	Schema    string        `json:"schema"`
+	ID        uuid.UUID     `json:"id"`
+	URL       string        `json:"url"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Body      string        `json:"body"`
+	Author    ChirpAuthorV1 `json:"author"`
+}
+
+// ChirpAuthorV1 is a chirp's author as they were when it was posted.
+// CapturedAt is nil if the server had no snapshot and returned the author
+// as they are now.
+type ChirpAuthorV1 struct {
+	ID         uuid.UUID  `json:"id"`
+	Email      string     `json:"email"`
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+}
+
+// ValidatedChirp is a cleaned chirp body and its counted length
+type ValidatedChirp struct {
+	Body   string `json:"body"`
+	Length int    `json:"length"`
+}
+
+// InboundEmail is an email forwarded by the email provider
+type InboundEmail struct {
+	From        string              `json:"from"`
+	To          string              `json:"to"`
+	Subject     string              `json:"subject"`
+	Text        string              `json:"text"`
+	Attachments []InboundAttachment `json:"attachments"`
+}
+
+// InboundAttachment describes an email attachment
+type InboundAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// PeriodCount is a count for a day or week
+type PeriodCount struct {
+	Period time.Time `json:"period"`
+	Value  int64     `json:"value"`
+}
+
+// TopAuthor is a user ranked by recent chirps
+type TopAuthor struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	Chirps int64     `json:"chirps"`
+}
+
+// DBPoolStats are the server's database pool counters
+type DBPoolStats struct {
+	OpenConnections int           `json:"open_connections"`
+	InUse           int           `json:"in_use"`
+	Idle            int           `json:"idle"`
+	WaitCount       int64         `json:"wait_count"`
+	WaitDuration    time.Duration `json:"wait_duration_ns"`
+}
+
+// RetryStats are the server's retry counts for one retry policy
+type RetryStats struct {
+	Name     string `json:"name"`
+	Attempts int64  `json:"attempts"`
+	Retries  int64  `json:"retries"`
+	GiveUps  int64  `json:"give_ups"`
+}
+
+// RouteStats are the request counts for one route and method
+type RouteStats struct {
+	Route        string `json:"route"`
+	Method       string `json:"method"`
+	Requests     int64  `json:"requests"`
+	ClientErrors int64  `json:"client_errors"`
+	ServerErrors int64  `json:"server_errors"`
+}
+
+// CacheStats are the server's hit and miss counts for one in-process cache
+type CacheStats struct {
+	Name    string `json:"name"`
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// Stats is the admin dashboard data. The user and chirp figures are for
+// Tenant; the rest cover the whole deployment.
+type Stats struct {
+	Tenant       string        `json:"tenant"`
+	Hits         int64         `json:"hits"`
+	Days         int           `json:"days"`
+	TotalUsers   int64         `json:"total_users"`
+	TotalChirps  int64         `json:"total_chirps"`
+	ChirpsPerDay []PeriodCount `json:"chirps_per_day"`
+	TopAuthors   []TopAuthor   `json:"top_authors"`
+	DBPool       DBPoolStats   `json:"db_pool"`
+	Retries      []RetryStats  `json:"retries"`
+	Routes       []RouteStats  `json:"routes"`
+	Caches       []CacheStats  `json:"caches"`
+}
+
+// FailedJob is a background job that ran out of attempts
+type FailedJob struct {
+	ID        uuid.UUID `json:"id"`
+	Kind      string    `json:"kind"`
+	Attempts  int32     `json:"attempts"`
+	LastError string    `json:"last_error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// JobsStatus is the background job queue status
+type JobsStatus struct {
+	Depth    int64            `json:"depth"`
+	Counts   map[string]int64 `json:"counts"`
+	Failures []FailedJob      `json:"failures"`
+}
+
+// AuthorViews summarizes daily views of an author's chirps
+type AuthorViews struct {
+	UserID      uuid.UUID     `json:"user_id"`
+	Days        int           `json:"days"`
+	TotalViews  int64         `json:"total_views"`
+	ViewsPerDay []PeriodCount `json:"views_per_day"`
+}
+
+// AuditEntry is an audit log entry
+type AuditEntry struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	RequestID string    `json:"request_id"`
+	Details   string    `json:"details"`
+}
+
+// AuditFilter narrows an audit log listing. Zero fields are ignored.
+type AuditFilter struct {
+	Action string
+	Actor  string
+	Target string
+	Since  time.Time
+	// Limit is the page size (1-200, server default 50)
+	Limit int
+}
+
+// AuditPage is one page of the audit log
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// Error is an error response from the server
+type Error struct {
+	StatusCode int
+	Message    string `json:"error"`
+	Code       string `json:"code,omitempty"`
+	Field      string `json:"field,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = "request failed"
+	}
+	if e.Field != "" {
+		msg = e.Field + " " + msg
+	}
+	return fmt.Sprintf("chirpy: %d: %s", e.StatusCode, msg)
+}
+
+// Report is a report filed against a chirp
+type Report struct {
+	ID         uuid.UUID  `json:"id"`
+	ChirpID    uuid.UUID  `json:"chirp_id"`
+	ReporterID uuid.UUID  `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// QueuedReport is a report in the admin review queue, with the reported
+// chirp. The chirp fields are empty if it was deleted.
+type QueuedReport struct {
+	Report
+	ChirpBody     string `json:"chirp_body"`
+	ChirpAuthorID string `json:"chirp_author_id"`
+	ChirpHidden   bool   `json:"chirp_hidden"`
+}
+
+// ReportsPage is one page of the review queue
+type ReportsPage struct {
+	Reports    []QueuedReport `json:"reports"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// Rule is an instance rule. Number counts from 1 and is ignored when
+// updating the rules.
+type Rule struct {
+	Number      int    `json:"number,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// ReportReason is a reason a chirp can be reported for
+type ReportReason struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// InstanceRules are the instance rules and report reasons
+type InstanceRules struct {
+	Rules         []Rule         `json:"rules"`
+	ReportReasons []ReportReason `json:"report_reasons"`
+}
+
+// PendingChirp is a chirp held by the classifier, waiting for a moderator
+type PendingChirp struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	HeldAt    time.Time `json:"held_at"`
+	Details   string    `json:"details"`
+}
+
+// PendingChirpsPage is one page of the moderation queue
+type PendingChirpsPage struct {
+	Chirps     []PendingChirp `json:"chirps"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
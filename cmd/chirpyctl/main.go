@@ -0,0 +1,402 @@
+// Command chirpyctl runs operator tasks against a Chirpy database: applying
+// migrations, managing tenants, creating users, rotating posting secrets,
+// purging expired data and printing stats. It reads the same environment and .env file as
+// the server.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/jobs"
+	"github.com/hydeh3r3/chirpy/internal/migrate"
+	"github.com/hydeh3r3/chirpy/internal/store"
+	schema "github.com/hydeh3r3/chirpy/sql"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const usage = `Usage: chirpyctl <command> [flags]
+
+Commands:
+  migrate                         apply pending database migrations
+  create-tenant -slug SLUG -name NAME [-host HOST]
+                                  create a tenant, served on HOST if given
+  list-tenants                    list tenants
+  create-user -email EMAIL [-tenant SLUG]
+                                  create a user
+  rotate-posting-secret -id ID    give a user a new posting address
+  purge                           delete expired idempotency keys and old finished jobs
+  stats [-tenant SLUG]            print user, chirp and job counts as JSON
+`
+
+// tenantSlug is what a tenant slug looks like, e.g. book-club
+var tenantSlug = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Stats windows, as on the admin dashboard
+const (
+	statsDays       = 30
+	statsTopAuthors = 10
+)
+
+// env is the opened database. queries is nil unless the driver is Postgres.
+type env struct {
+	cfg     config.Config
+	conn    *sql.DB
+	store   store.Store
+	queries *database.Queries
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	commands := map[string]func(context.Context, *env, []string) error{
+		"migrate":               runMigrate,
+		"create-tenant":         runCreateTenant,
+		"list-tenants":          runListTenants,
+		"create-user":           runCreateUser,
+		"rotate-posting-secret": runRotatePostingSecret,
+		"purge":                 runPurge,
+		"stats":                 runStats,
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "chirpyctl: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	e, err := open()
+	if err == nil {
+		defer e.conn.Close()
+		err = run(ctx, e, os.Args[2:])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "chirpyctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// open connects to the configured database
+func open() (*env, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Platform == config.PlatformDemo {
+		return nil, errors.New("demo mode has no database to manage")
+	}
+
+	e := &env{cfg: cfg}
+	if cfg.DBDriver == config.DriverSQLite {
+		if e.conn, err = store.OpenSQLite(cfg.DBURL); err != nil {
+			return nil, err
+		}
+		e.store = store.NewSQLite(e.conn)
+		return e, nil
+	}
+
+	if e.conn, err = sql.Open("postgres", cfg.DBURL); err != nil {
+		return nil, err
+	}
+	pg := store.NewPostgres(e.conn)
+	e.store, e.queries = pg, pg.Queries
+	return e, nil
+}
+
+// requirePostgres reports an error for commands that only apply to
+// Postgres
+func (e *env) requirePostgres() error {
+	if e.queries == nil {
+		return errors.New("needs Postgres (DB_DRIVER=postgres)")
+	}
+	return nil
+}
+
+func runMigrate(ctx context.Context, e *env, args []string) error {
+	if err := flag.NewFlagSet("migrate", flag.ExitOnError).Parse(args); err != nil {
+		return err
+	}
+
+	if e.queries == nil {
+		if err := store.MigrateSQLite(ctx, e.conn); err != nil {
+			return err
+		}
+		fmt.Println("sqlite schema is up to date")
+		return nil
+	}
+
+	applied, err := migrate.Up(ctx, e.conn, schema.Schema())
+	for _, version := range applied {
+		fmt.Printf("applied %d\n", version)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("no pending migrations")
+	}
+	return nil
+}
+
+func runCreateTenant(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("create-tenant", flag.ExitOnError)
+	slug := fs.String("slug", "", "the tenant's slug, as sent in the X-Chirpy-Tenant header")
+	name := fs.String("name", "", "the tenant's display name")
+	host := fs.String("host", "", "the host name the tenant is served on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !tenantSlug.MatchString(*slug) {
+		return errors.New("-slug must be up to 32 lowercase letters, digits and hyphens")
+	}
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	hostName := strings.TrimSuffix(strings.ToLower(*host), ".")
+	tenant, err := e.store.CreateTenant(ctx, database.CreateTenantParams{
+		ID:        uuid.New(),
+		Slug:      *slug,
+		Name:      *name,
+		Host:      sql.NullString{String: hostName, Valid: hostName != ""},
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	printTenant(tenant)
+	return nil
+}
+
+func runListTenants(ctx context.Context, e *env, args []string) error {
+	if err := flag.NewFlagSet("list-tenants", flag.ExitOnError).Parse(args); err != nil {
+		return err
+	}
+
+	tenants, err := e.store.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+	for i, tenant := range tenants {
+		if i > 0 {
+			fmt.Println()
+		}
+		printTenant(tenant)
+	}
+	return nil
+}
+
+// printTenant prints a tenant's ID, slug, name and host
+func printTenant(tenant database.Tenant) {
+	fmt.Printf("id:   %s\n", tenant.ID)
+	fmt.Printf("slug: %s\n", tenant.Slug)
+	fmt.Printf("name: %s\n", tenant.Name)
+	if tenant.Host.Valid {
+		fmt.Printf("host: %s\n", tenant.Host.String)
+	}
+}
+
+// tenant finds a tenant by slug
+func (e *env) tenant(ctx context.Context, slug string) (database.Tenant, error) {
+	tenant, err := e.store.GetTenantBySlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Tenant{}, fmt.Errorf("no tenant with slug %q", slug)
+	}
+	return tenant, err
+}
+
+func runCreateUser(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("create-user", flag.ExitOnError)
+	email := fs.String("email", "", "the user's email address")
+	tenantSlug := fs.String("tenant", "default", "the slug of the user's tenant")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+	tenant, err := e.tenant(ctx, *tenantSlug)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	user, err := e.store.CreateUser(ctx, database.CreateUserParams{
+		ID:            uuid.New(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Email:         *email,
+		PostingSecret: store.NewPostingSecret(),
+		TenantID:      tenant.ID,
+	})
+	if err != nil {
+		return err
+	}
+	e.printUser(user)
+	return nil
+}
+
+func runRotatePostingSecret(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("rotate-posting-secret", flag.ExitOnError)
+	id := fs.String("id", "", "the user's ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	userID, err := uuid.Parse(*id)
+	if err != nil {
+		return fmt.Errorf("-id: %w", err)
+	}
+
+	user, err := e.store.UpdatePostingSecret(ctx, database.UpdatePostingSecretParams{
+		ID:            userID,
+		PostingSecret: store.NewPostingSecret(),
+		UpdatedAt:     time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user with ID %s", userID)
+	}
+	if err != nil {
+		return err
+	}
+	e.printUser(user)
+	return nil
+}
+
+// printUser prints a user's ID, email and posting address
+func (e *env) printUser(user database.User) {
+	fmt.Printf("id:              %s\n", user.ID)
+	fmt.Printf("email:           %s\n", user.Email)
+	if e.cfg.EmailGateway.Domain != "" {
+		fmt.Printf("posting address: %s@%s\n", user.PostingSecret, e.cfg.EmailGateway.Domain)
+	} else {
+		fmt.Printf("posting secret:  %s\n", user.PostingSecret)
+	}
+}
+
+func runPurge(ctx context.Context, e *env, args []string) error {
+	if err := flag.NewFlagSet("purge", flag.ExitOnError).Parse(args); err != nil {
+		return err
+	}
+	if err := e.requirePostgres(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	keys, err := e.queries.DeleteExpiredIdempotencyKeys(ctx, now)
+	if err != nil {
+		return fmt.Errorf("purging idempotency keys: %w", err)
+	}
+	done, err := jobs.PurgeDone(ctx, e.queries, now)
+	if err != nil {
+		return fmt.Errorf("purging jobs: %w", err)
+	}
+	views, err := jobs.PurgeViews(ctx, e.queries, now)
+	if err != nil {
+		return fmt.Errorf("purging chirp views: %w", err)
+	}
+	fmt.Printf("deleted %d expired idempotency keys, %d finished jobs and %d chirp view rows\n", keys, done, views)
+	return nil
+}
+
+// stats is the output of the stats command. Migrations cover the whole
+// deployment; the rest is for one tenant.
+type stats struct {
+	Tenant            string           `json:"tenant"`
+	TotalUsers        int64            `json:"total_users"`
+	TotalChirps       int64            `json:"total_chirps"`
+	ChirpsPerDay      map[string]int64 `json:"chirps_per_day,omitempty"`
+	TopAuthors        map[string]int64 `json:"top_authors,omitempty"`
+	Jobs              map[string]int64 `json:"jobs,omitempty"`
+	PendingMigrations []int64          `json:"pending_migrations,omitempty"`
+}
+
+func runStats(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	tenantSlug := fs.String("tenant", "default", "the slug of the tenant to count")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tenant, err := e.tenant(ctx, *tenantSlug)
+	if err != nil {
+		return err
+	}
+
+	s := stats{Tenant: tenant.Slug}
+	if s.TotalUsers, err = e.store.CountUsers(ctx, tenant.ID); err != nil {
+		return err
+	}
+	if s.TotalChirps, err = e.store.CountChirps(ctx, tenant.ID); err != nil {
+		return err
+	}
+
+	// The rest is only kept in Postgres
+	if e.queries != nil {
+		since := time.Now().UTC().AddDate(0, 0, -statsDays)
+		daily, err := e.queries.GetDailyChirpCounts(ctx, database.GetDailyChirpCountsParams{
+			TenantID:  tenant.ID,
+			CreatedAt: since,
+		})
+		if err != nil {
+			return err
+		}
+		s.ChirpsPerDay = map[string]int64{}
+		for _, row := range daily {
+			s.ChirpsPerDay[row.Day.Format(time.DateOnly)] = row.Chirps
+		}
+
+		authors, err := e.queries.GetTopAuthors(ctx, database.GetTopAuthorsParams{
+			TenantID:  tenant.ID,
+			CreatedAt: since,
+			Limit:     statsTopAuthors,
+		})
+		if err != nil {
+			return err
+		}
+		s.TopAuthors = map[string]int64{}
+		for _, row := range authors {
+			s.TopAuthors[row.Email] = row.Chirps
+		}
+
+		counts, err := e.queries.GetJobCounts(ctx, tenant.ID)
+		if err != nil {
+			return err
+		}
+		s.Jobs = map[string]int64{}
+		for _, row := range counts {
+			s.Jobs[row.Status] = row.Count
+		}
+
+		want, err := migrate.Versions(schema.Schema())
+		if err != nil {
+			return err
+		}
+		history, err := migrate.History(ctx, e.conn)
+		if err != nil {
+			return err
+		}
+		s.PendingMigrations = migrate.Pending(want, history)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
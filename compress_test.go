@@ -0,0 +1,109 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"br", ""},
+		{"GZIP", "gzip"},
+		{"x-gzip", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"deflate;q=0.5, gzip; q=0.8", "gzip"},
+		{"gzip;q=0", ""},
+		{"gzip;q=0, deflate", "deflate"},
+		{"gzip;q=0.0, deflate;q=0", ""},
+		{"gzip;q=abc", ""},
+		{"gzip;q=2", ""},
+		{"*", "gzip"},
+		{"*;q=0", ""},
+		{"*, gzip;q=0", "deflate"},
+		{"identity", ""},
+		{"identity, gzip;q=0.5", ""},
+		{"identity;q=0.5, gzip", "gzip"},
+		{"identity;q=0, gzip;q=0.1", "gzip"},
+		{"*;q=0.8, gzip;q=0.5", "deflate"},
+		{"identity;q=0.9, *;q=0.5", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMiddlewareCompress(t *testing.T) {
+	large := `{"body":"` + strings.Repeat("a", 2*compressMinBytes) + `"}`
+	handler := middlewareCompress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/encoded":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "br")
+		case "/stream":
+			// A streamed response flushes before it's big enough to compress
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("first\n"))
+			http.NewResponseController(w).Flush()
+		default:
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.Write([]byte(large))
+	}))
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/", "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("headers = %v, want gzip varying by Accept-Encoding", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := io.ReadAll(zr); err != nil || string(body) != large {
+		t.Errorf("decompressed body is %d bytes (%v), want the original", len(body), err)
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantBody       string
+		wantEncoding   string
+	}{
+		{"no header", "/", "", large, ""},
+		{"refused", "/", "gzip;q=0", large, ""},
+		{"already encoded", "/encoded", "gzip", large, "br"},
+		{"streaming", "/stream", "gzip", "first\n" + large, ""},
+	}
+	for _, tt := range tests {
+		rec := get(tt.path, tt.acceptEncoding)
+		if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+			t.Errorf("%s: Content-Encoding = %q, want %q", tt.name, got, tt.wantEncoding)
+		}
+		if rec.Body.String() != tt.wantBody {
+			t.Errorf("%s: body was changed", tt.name)
+		}
+	}
+}
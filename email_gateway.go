@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+)
+
+// maxInboundEmailBytes bounds webhook payloads, which may carry inline attachments
+const maxInboundEmailBytes = 10 << 20
+
+// inboundEmailRequest represents an email forwarded by the email provider's webhook
+type inboundEmailRequest struct {
+	From        string                   `json:"from"`
+	To          string                   `json:"to"`
+	Subject     string                   `json:"subject"`
+	Text        string                   `json:"text"`
+	Attachments []inboundEmailAttachment `json:"attachments"`
+}
+
+// inboundEmailAttachment describes an attachment; attachments are never posted
+type inboundEmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// postingAddress returns the secret address a user can email chirps to, or
+// "" when the email gateway is disabled
+func (cfg *apiConfig) postingAddress(user database.User) string {
+	if cfg.emailDomain == "" {
+		return ""
+	}
+	return user.PostingSecret + "@" + cfg.emailDomain
+}
+
+// emailChirpBody turns an email into chirp text: the plain-text body without
+// quoted replies or signature, falling back to the subject
+func emailChirpBody(subject, text string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if line == "-- " || line == "--" {
+			break
+		}
+		if strings.HasPrefix(line, ">") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	body := strings.TrimSpace(strings.Join(lines, "\n"))
+	if body == "" {
+		body = strings.TrimSpace(subject)
+	}
+	return body
+}
+
+// inboundEmailHandler converts emails sent to a user's posting address into chirps
+func (cfg *apiConfig) inboundEmailHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if cfg.emailDomain == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	// Only the email provider knows the webhook secret
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.emailSecret)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Invalid webhook secret"})
+		return
+	}
+
+	var req inboundEmailRequest
+	err := request.DecodeJSON(r, &req, maxInboundEmailBytes)
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Resolve the user from the secret local part of the recipient
+	to, err := mail.ParseAddress(req.To)
+	if err != nil {
+		respondWithRequestError(w, &request.FieldError{Field: "to", Message: "must be an email address"})
+		return
+	}
+	secret, domain, _ := strings.Cut(to.Address, "@")
+	if !strings.EqualFold(domain, cfg.emailDomain) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Unknown posting address"})
+		return
+	}
+	user, err := cfg.store.GetUserByPostingSecret(r.Context(), secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Unknown posting address"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get user"})
+		return
+	}
+
+	// The sender must also be the account's own email address
+	from, err := mail.ParseAddress(req.From)
+	if err != nil || !strings.EqualFold(from.Address, user.Email) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Sender does not match the account email"})
+		return
+	}
+
+	body := emailChirpBody(req.Subject, req.Text)
+	if body == "" {
+		respondWithRequestError(w, &request.FieldError{Field: "text", Message: "is empty"})
+		return
+	}
+
+	chirp, pending, err := cfg.createChirp(r.Context(), user.TenantID, user.ID, body, nil)
+	if err != nil {
+		respondWithCreateChirpError(w, err)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(chirpResponse{
+		ID:        chirp.ID.String(),
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+		UserID:    chirp.UserID.String(),
+		Pending:   pending,
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+)
+
+//go:embed templates/emails/*.html
+var emailFS embed.FS
+
+// Email template names
+const (
+	emailVerification  = "verification"
+	emailPasswordReset = "password_reset"
+	emailDigest        = "digest"
+)
+
+// linkEmail is the data for emails built around a single action link
+type linkEmail struct {
+	Email     string
+	Link      string
+	ExpiresIn string
+}
+
+// digestChirp is a chirp listed in a digest email
+type digestChirp struct {
+	Author       string
+	Body         string
+	RechirpCount int64
+	Link         string
+}
+
+// digestEmail is the data for the digest email
+type digestEmail struct {
+	Email  string
+	Since  time.Time
+	Chirps []digestChirp
+}
+
+// emailTemplate is a parsed email and the sample data used to preview it
+type emailTemplate struct {
+	subject string
+	tmpl    *template.Template
+	sample  func() any
+}
+
+// emailTemplates holds every email, each parsed on top of the shared layout
+var emailTemplates = parseEmailTemplates(map[string]emailTemplate{
+	emailVerification: {
+		subject: "Verify your Chirpy email",
+		sample: func() any {
+			return linkEmail{Email: "walt@example.com", Link: "https://chirpy.example.com/verify?token=sample", ExpiresIn: "24 hours"}
+		},
+	},
+	emailPasswordReset: {
+		subject: "Reset your Chirpy password",
+		sample: func() any {
+			return linkEmail{Email: "walt@example.com", Link: "https://chirpy.example.com/reset?token=sample", ExpiresIn: "1 hour"}
+		},
+	},
+	emailDigest: {
+		subject: "Your Chirpy digest",
+		sample: func() any {
+			return digestEmail{
+				Email: "walt@example.com",
+				Since: time.Now().AddDate(0, 0, -7),
+				Chirps: []digestChirp{
+					{Author: "saul@example.com", Body: "I'm the one who knocks!", RechirpCount: 12, Link: "https://chirpy.example.com/chirps/1"},
+					{Author: "jesse@example.com", Body: "Gale! <script>alert('escaped')</script>", RechirpCount: 3, Link: "https://chirpy.example.com/chirps/2"},
+				},
+			}
+		},
+	},
+})
+
+// parseEmailTemplates parses templates/emails/<name>.html with the layout
+// for each email
+func parseEmailTemplates(emails map[string]emailTemplate) map[string]emailTemplate {
+	layout := template.Must(template.ParseFS(emailFS, "templates/emails/layout.html"))
+	for name, email := range emails {
+		email.tmpl = template.Must(template.Must(layout.Clone()).ParseFS(emailFS, "templates/emails/"+name+".html"))
+		emails[name] = email
+	}
+	return emails
+}
+
+// renderEmail returns the subject and HTML body of the named email. Data is
+// escaped by html/template, so user content such as chirp bodies is safe.
+func renderEmail(name string, data any) (subject, body string, err error) {
+	email, ok := emailTemplates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown email %q", name)
+	}
+	var buf bytes.Buffer
+	if err := email.tmpl.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return "", "", err
+	}
+	return email.subject, buf.String(), nil
+}
+
+// emailPreviewHandler renders an email with sample data so designers can
+// check it in a browser (dev mode only)
+func (cfg *apiConfig) emailPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if cfg.platform != config.PlatformDev {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Email previews only available in dev mode"})
+		return
+	}
+
+	name := r.PathValue("template")
+	email, ok := emailTemplates[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Unknown email template"})
+		return
+	}
+	subject, body, err := renderEmail(name, email.sample())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to render email"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Email-Subject", subject)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+)
+
+func TestRenderEmailEscapesContent(t *testing.T) {
+	_, body, err := renderEmail(emailDigest, digestEmail{
+		Email:  "a@example.com",
+		Chirps: []digestChirp{{Author: "b@example.com", Body: "<script>alert(1)</script>", Link: "javascript:alert(1)"}},
+	})
+	if err != nil {
+		t.Fatalf("renderEmail: %v", err)
+	}
+	if strings.Contains(body, "<script>") {
+		t.Error("chirp body was not escaped")
+	}
+	if strings.Contains(body, "javascript:") {
+		t.Error("unsafe link was not filtered")
+	}
+}
+
+func TestEmailPreview(t *testing.T) {
+	tests := []struct {
+		name       string
+		platform   string
+		template   string
+		wantStatus int
+	}{
+		{"verification", config.PlatformDev, emailVerification, http.StatusOK},
+		{"password reset", config.PlatformDev, emailPasswordReset, http.StatusOK},
+		{"digest", config.PlatformDev, emailDigest, http.StatusOK},
+		{"unknown", config.PlatformDev, "nope", http.StatusNotFound},
+		{"prod", config.PlatformProd, emailDigest, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Platform = tt.platform
+			rec := newTestServer(t, cfg).do(http.MethodGet, "/admin/emails/preview/"+tt.template, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Header().Get("X-Email-Subject") == "" {
+				t.Error("missing X-Email-Subject")
+			}
+		})
+	}
+}
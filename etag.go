@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// weakETag builds a weak validator from the time a resource last changed,
+// plus any derived counts that change without touching updated_at
+func weakETag(updatedAt time.Time, parts ...int64) string {
+	tag := fmt.Sprintf("%x", updatedAt.UnixNano())
+	for _, p := range parts {
+		tag += fmt.Sprintf("-%x", p)
+	}
+	return `W/"` + tag + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// weak comparison
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotModified sets the ETag and Cache-Control headers and, if the client
+// already has this version, writes a 304 and reports true. The response
+// varies by the tenant header, since it can pick another tenant's resource
+// at the same URL.
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, no-cache")
+	w.Header().Add("Vary", tenantHeader)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
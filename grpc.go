@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/chirpypb"
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcTenantKey is the metadata key that picks a tenant by slug
+const grpcTenantKey = "x-chirpy-tenant"
+
+// chirpyService serves the gRPC API from the same store and rules as the
+// HTTP handlers
+type chirpyService struct {
+	chirpypb.UnimplementedChirpyServiceServer
+	cfg *apiConfig
+}
+
+// newGRPCServer returns a gRPC server with the Chirpy service registered
+func (cfg *apiConfig) newGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(cfg.grpcAdmin, cfg.grpcTenant, cfg.grpcTimeout))
+	srv := grpc.NewServer(opts...)
+	chirpypb.RegisterChirpyServiceServer(srv, &chirpyService{cfg: cfg})
+	return srv
+}
+
+// grpcAdmin is middlewareAdmin for the gRPC API, which is meant for
+// internal services. The token comes as "authorization: Bearer <token>".
+func (cfg *apiConfig) grpcAdmin(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if len(cfg.adminTokens) == 0 {
+		if cfg.platform == config.PlatformProd {
+			return nil, status.Error(codes.PermissionDenied, "the gRPC API needs ADMIN_TOKENS in prod mode")
+		}
+		return handler(ctx, req)
+	}
+
+	token, _ := strings.CutPrefix(metadataValue(ctx, "authorization"), "Bearer ")
+	name, ok := cfg.adminForToken(token)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+	return handler(context.WithValue(ctx, adminKey{}, name), req)
+}
+
+// grpcTenant resolves the tenant named by the tenant metadata key. Calls
+// without one use the default tenant.
+func (cfg *apiConfig) grpcTenant(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	slug := strings.TrimSpace(metadataValue(ctx, grpcTenantKey))
+	if slug == "" {
+		slug = defaultTenantSlug
+	}
+	tenant, err := cfg.tenantBySlug(ctx, slug)
+	if errors.Is(err, errUnknownTenant) {
+		return nil, status.Error(codes.NotFound, "unknown tenant")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to resolve tenant")
+	}
+	return handler(context.WithValue(ctx, tenantKey{}, tenant), req)
+}
+
+// grpcTimeout bounds each call like middlewareTimeout bounds a request
+func (cfg *apiConfig) grpcTimeout(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if cfg.requestTimeout <= 0 {
+		return handler(ctx, req)
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+	return handler(ctx, req)
+}
+
+// metadataValue returns the first value of key in the incoming metadata
+func metadataValue(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// parseUUIDField parses an ID from a request message
+func parseUUIDField(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "%s must be a valid UUID", field)
+	}
+	return id, nil
+}
+
+// userToProto converts a stored user to its message
+func userToProto(user database.User) *chirpypb.User {
+	return &chirpypb.User{
+		Id:        user.ID.String(),
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
+
+// chirpToProto converts a stored chirp and its counts to its message
+func chirpToProto(chirp database.Chirp, rechirps, views int64) *chirpypb.Chirp {
+	return &chirpypb.Chirp{
+		Id:           chirp.ID.String(),
+		UserId:       chirp.UserID.String(),
+		Body:         chirp.Body,
+		CreatedAt:    timestamppb.New(chirp.CreatedAt),
+		UpdatedAt:    timestamppb.New(chirp.UpdatedAt),
+		RechirpCount: rechirps,
+		ViewCount:    views,
+	}
+}
+
+// CreateUser creates a user in the request's tenant
+func (s *chirpyService) CreateUser(ctx context.Context, req *chirpypb.CreateUserRequest) (*chirpypb.User, error) {
+	user, err := s.cfg.createUser(ctx, tenantID(ctx), req.GetEmail())
+	var fieldErr *request.FieldError
+	if errors.As(err, &fieldErr) {
+		return nil, status.Error(codes.InvalidArgument, fieldErr.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create user")
+	}
+	return userToProto(user), nil
+}
+
+// GetUser returns a user of the request's tenant
+func (s *chirpyService) GetUser(ctx context.Context, req *chirpypb.GetUserRequest) (*chirpypb.User, error) {
+	userID, err := parseUUIDField("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.cfg.store.GetUser(ctx, database.GetUserParams{ID: userID, TenantID: tenantID(ctx)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+	return userToProto(user), nil
+}
+
+// CreateChirp validates, cleans and stores a chirp
+func (s *chirpyService) CreateChirp(ctx context.Context, req *chirpypb.CreateChirpRequest) (*chirpypb.CreateChirpResponse, error) {
+	userID, err := parseUUIDField("user_id", req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	chirp, pending, err := s.cfg.createChirp(ctx, tenantID(ctx), userID, req.GetBody(), nil)
+	if err != nil {
+		return nil, createChirpStatus(err)
+	}
+	return &chirpypb.CreateChirpResponse{
+		Chirp:   chirpToProto(chirp, 0, 0),
+		Pending: pending,
+		Warning: s.cfg.strikeWarning(ctx, userID),
+	}, nil
+}
+
+// createChirpStatus maps errors from createChirp to gRPC statuses, as
+// respondWithCreateChirpError does to HTTP responses
+func createChirpStatus(err error) error {
+	var cooldown *postingCooldownError
+	switch {
+	case errors.Is(err, errSuspended):
+		return status.Error(codes.PermissionDenied, "your account is suspended")
+	case errors.As(err, &cooldown):
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("posting is paused for your account until %s", cooldown.until.Format(time.RFC3339)))
+	case errors.Is(err, errChirpTooLong):
+		return status.Error(codes.InvalidArgument, "chirp is too long")
+	case errors.Is(err, errUnknownAuthor):
+		return status.Error(codes.NotFound, "user not found")
+	case errors.Is(err, errDuplicateChirp):
+		return status.Error(codes.AlreadyExists, "you just posted this chirp")
+	default:
+		return status.Error(codes.Internal, "failed to create chirp")
+	}
+}
+
+// GetChirp returns a chirp unless it is held for review
+func (s *chirpyService) GetChirp(ctx context.Context, req *chirpypb.GetChirpRequest) (*chirpypb.Chirp, error) {
+	chirpID, err := parseUUIDField("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	chirp, err := s.cfg.getVisibleChirp(ctx, chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "chirp not found")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get chirp")
+	}
+	rechirps, err := s.cfg.store.CountRechirps(ctx, chirp.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to count rechirps")
+	}
+	views, err := s.cfg.viewCount(ctx, chirp.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to count views")
+	}
+	return chirpToProto(chirp, rechirps, views), nil
+}
+
+// BatchGetChirps looks up many chirps by ID at once. Missing and held
+// chirps are left out.
+func (s *chirpyService) BatchGetChirps(ctx context.Context, req *chirpypb.BatchGetChirpsRequest) (*chirpypb.BatchGetChirpsResponse, error) {
+	if n := len(req.GetIds()); n == 0 || n > maxBatchChirps {
+		return nil, status.Errorf(codes.InvalidArgument, "ids must have between 1 and %d IDs", maxBatchChirps)
+	}
+	ids := make([]uuid.UUID, len(req.GetIds()))
+	for i, value := range req.GetIds() {
+		id, err := parseUUIDField("ids", value)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+
+	chirps, err := s.cfg.store.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+		TenantID: tenantID(ctx),
+		Ids:      ids,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get chirps")
+	}
+	counts, err := s.cfg.store.CountRechirpsByChirpIDs(ctx, ids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to count rechirps")
+	}
+	views, err := s.cfg.viewCounts(ctx, ids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to count views")
+	}
+	held, err := s.cfg.heldChirpIDs(ctx, ids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get chirps")
+	}
+
+	byID := make(map[uuid.UUID]database.Chirp, len(chirps))
+	for _, chirp := range chirps {
+		byID[chirp.ID] = chirp
+	}
+	rechirps := make(map[uuid.UUID]int64, len(counts))
+	for _, row := range counts {
+		rechirps[row.ChirpID] = row.Count
+	}
+
+	resp := &chirpypb.BatchGetChirpsResponse{}
+	for _, id := range ids {
+		chirp, ok := byID[id]
+		if !ok || held[id] {
+			continue
+		}
+		resp.Chirps = append(resp.Chirps, chirpToProto(chirp, rechirps[id], views[id]))
+	}
+	return resp, nil
+}
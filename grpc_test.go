@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/chirpypb"
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCTestClient serves the gRPC API over an in-memory connection
+func newGRPCTestClient(t *testing.T, c config.Config, st store.Store) chirpypb.ChirpyServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := newAPIConfig(c, st).newGRPCServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return chirpypb.NewChirpyServiceClient(conn)
+}
+
+func TestGRPC(t *testing.T) {
+	client := newGRPCTestClient(t, testConfig(), store.NewMemory())
+	ctx := context.Background()
+
+	user, err := client.CreateUser(ctx, &chirpypb.CreateUserRequest{Email: "a@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetUser(ctx, &chirpypb.GetUserRequest{Id: user.Id})
+	if err != nil || got.Email != "a@example.com" {
+		t.Fatalf("GetUser = %v, %v", got, err)
+	}
+
+	created, err := client.CreateChirp(ctx, &chirpypb.CreateChirpRequest{UserId: user.Id, Body: "This is a kerfuffle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Chirp.Body != "This is a ****" || created.Pending {
+		t.Errorf("CreateChirp = %v, want a cleaned, published chirp", created)
+	}
+	chirp, err := client.GetChirp(ctx, &chirpypb.GetChirpRequest{Id: created.Chirp.Id})
+	if err != nil || chirp.Body != created.Chirp.Body || chirp.UserId != user.Id {
+		t.Errorf("GetChirp = %v, %v", chirp, err)
+	}
+
+	list, err := client.BatchGetChirps(ctx, &chirpypb.BatchGetChirpsRequest{Ids: []string{uuid.NewString(), created.Chirp.Id}})
+	if err != nil || len(list.Chirps) != 1 || list.Chirps[0].Id != created.Chirp.Id {
+		t.Errorf("BatchGetChirps = %v, %v, want only the chirp that exists", list, err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"bad user id", func() error {
+			_, err := client.GetUser(ctx, &chirpypb.GetUserRequest{Id: "nope"})
+			return err
+		}, codes.InvalidArgument},
+		{"bad email", func() error {
+			_, err := client.CreateUser(ctx, &chirpypb.CreateUserRequest{Email: "nope"})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown user", func() error {
+			_, err := client.GetUser(ctx, &chirpypb.GetUserRequest{Id: uuid.NewString()})
+			return err
+		}, codes.NotFound},
+		{"unknown author", func() error {
+			_, err := client.CreateChirp(ctx, &chirpypb.CreateChirpRequest{UserId: uuid.NewString(), Body: "hi"})
+			return err
+		}, codes.NotFound},
+		{"too long", func() error {
+			_, err := client.CreateChirp(ctx, &chirpypb.CreateChirpRequest{UserId: user.Id, Body: strings.Repeat("a", maxChirpLength+1)})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown chirp", func() error {
+			_, err := client.GetChirp(ctx, &chirpypb.GetChirpRequest{Id: uuid.NewString()})
+			return err
+		}, codes.NotFound},
+		{"no ids", func() error {
+			_, err := client.BatchGetChirps(ctx, &chirpypb.BatchGetChirpsRequest{})
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		if got := status.Code(tt.call()); got != tt.want {
+			t.Errorf("%s: code = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGRPCAdminTokens(t *testing.T) {
+	c := testConfig()
+	c.Platform = config.PlatformProd
+	client := newGRPCTestClient(t, c, store.NewMemory())
+	_, err := client.CreateUser(context.Background(), &chirpypb.CreateUserRequest{Email: "a@example.com"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("prod without tokens: code = %v, want PermissionDenied", status.Code(err))
+	}
+
+	c.AdminTokens = map[string]string{"alice": "alice-token-0123456789"}
+	client = newGRPCTestClient(t, c, store.NewMemory())
+	tests := []struct {
+		name  string
+		token string
+		want  codes.Code
+	}{
+		{"no token", "", codes.Unauthenticated},
+		{"wrong token", "Bearer nope", codes.Unauthenticated},
+		{"token", "Bearer alice-token-0123456789", codes.OK},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.token)
+		}
+		_, err := client.CreateUser(ctx, &chirpypb.CreateUserRequest{Email: "a@example.com"})
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%s: code = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGRPCTenant(t *testing.T) {
+	st := store.NewMemory()
+	_, err := st.CreateTenant(context.Background(), database.CreateTenantParams{
+		ID:        uuid.New(),
+		Slug:      "acme",
+		Name:      "Acme",
+		Host:      sql.NullString{},
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newGRPCTestClient(t, testConfig(), st)
+
+	acme := metadata.AppendToOutgoingContext(context.Background(), grpcTenantKey, "acme")
+	user, err := client.CreateUser(acme, &chirpypb.CreateUserRequest{Email: "a@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetUser(acme, &chirpypb.GetUserRequest{Id: user.Id}); err != nil {
+		t.Errorf("same tenant: %v", err)
+	}
+	if _, err := client.GetUser(context.Background(), &chirpypb.GetUserRequest{Id: user.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("default tenant: code = %v, want NotFound", status.Code(err))
+	}
+
+	unknown := metadata.AppendToOutgoingContext(context.Background(), grpcTenantKey, "nope")
+	if _, err := client.GetUser(unknown, &chirpypb.GetUserRequest{Id: user.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown tenant: code = %v, want NotFound", status.Code(err))
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// Reasons a chirp is held back from public reads
+const (
+	holdReports    = "reports"    // enough open reports, pending review
+	holdRemoved    = "removed"    // a moderator took it down
+	holdModeration = "moderation" // flagged by the classifier, pending review
+)
+
+// chirpReviews keeps chirp reports and the holds that hide chirps while
+// they're reviewed
+type chirpReviews interface {
+	HoldChirp(ctx context.Context, arg database.HoldChirpParams) error
+	ReleaseChirp(ctx context.Context, arg database.ReleaseChirpParams) (int64, error)
+	UpdateChirpHoldReason(ctx context.Context, arg database.UpdateChirpHoldReasonParams) (int64, error)
+	IsChirpHeld(ctx context.Context, chirpID uuid.UUID) (bool, error)
+	GetChirpHold(ctx context.Context, arg database.GetChirpHoldParams) (database.ChirpHold, error)
+	GetHeldChirpIDs(ctx context.Context, chirpIDs []uuid.UUID) ([]uuid.UUID, error)
+	ListChirpHolds(ctx context.Context, arg database.ListChirpHoldsParams) ([]database.ChirpHold, error)
+	ListReportReasons(ctx context.Context, tenantID uuid.UUID) ([]database.ReportReason, error)
+	CreateReport(ctx context.Context, arg database.CreateReportParams) (int64, error)
+	CountOpenReportAddresses(ctx context.Context, chirpID uuid.UUID) (int64, error)
+	GetReport(ctx context.Context, arg database.GetReportParams) (database.Report, error)
+	ListReports(ctx context.Context, arg database.ListReportsParams) ([]database.Report, error)
+	ReviewChirpReports(ctx context.Context, arg database.ReviewChirpReportsParams) (int64, error)
+}
+
+// reviewTx runs fn in a store transaction, with reviews that are part of
+// it when the store is Postgres
+func (cfg *apiConfig) reviewTx(ctx context.Context, fn func(chirpReviews) error) error {
+	return cfg.store.WithTx(ctx, func(tx store.Store) error {
+		if q := store.PostgresQueries(tx); q != nil {
+			return fn(q)
+		}
+		return fn(cfg.reviews)
+	})
+}
+
+// holdChirp hides a chirp from public reads using q, which is cfg.reviews
+// or a transaction. details says why, for the moderators.
+func holdChirp(ctx context.Context, q chirpReviews, chirpID uuid.UUID, reason, details string) error {
+	return q.HoldChirp(ctx, database.HoldChirpParams{
+		ChirpID:   chirpID,
+		Reason:    reason,
+		Details:   details,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// getVisibleChirp returns a chirp for a public read, with sql.ErrNoRows if
+// it doesn't exist in the request's tenant or is held. Without Postgres
+// nothing is ever held.
+func (cfg *apiConfig) getVisibleChirp(ctx context.Context, chirpID uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.store.GetChirp(ctx, database.GetChirpParams{ID: chirpID, TenantID: tenantID(ctx)})
+	if err != nil || cfg.reviews == nil {
+		return chirp, err
+	}
+
+	held, err := cfg.reviews.IsChirpHeld(ctx, chirpID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if held {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return chirp, nil
+}
+
+// heldChirpIDs returns which of ids are held
+func (cfg *apiConfig) heldChirpIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	if len(ids) == 0 || cfg.reviews == nil {
+		return nil, nil
+	}
+
+	rows, err := cfg.reviews.GetHeldChirpIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	held := make(map[uuid.UUID]bool, len(rows))
+	for _, id := range rows {
+		held[id] = true
+	}
+	return held, nil
+}
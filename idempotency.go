@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// Idempotency key settings
+const (
+	idempotencyKeyTTL       = 24 * time.Hour
+	idempotencyKeyMaxLength = 255
+	idempotencyPurgeEvery   = time.Hour
+)
+
+// idempotencyKeys stores idempotency keys and the responses they replay
+type idempotencyKeys interface {
+	CreateIdempotencyKey(ctx context.Context, arg database.CreateIdempotencyKeyParams) (int64, error)
+	GetIdempotencyKey(ctx context.Context, arg database.GetIdempotencyKeyParams) (database.IdempotencyKey, error)
+	SaveIdempotentResponse(ctx context.Context, arg database.SaveIdempotentResponseParams) error
+	DeleteIdempotencyKey(ctx context.Context, arg database.DeleteIdempotencyKeyParams) error
+}
+
+// responseRecorder passes a response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// middlewareIdempotency replays the stored response when a request repeats an
+// Idempotency-Key header, instead of running the handler again. Keys are
+// scoped to the request's tenant and endpoint.
+func (cfg *apiConfig) middlewareIdempotency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		if len(key) > idempotencyKeyMaxLength {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Idempotency key is too long", Field: "Idempotency-Key"})
+			return
+		}
+
+		// Hash the body so a key can't be reused for a different request
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, request.DefaultMaxBodyBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithRequestError(w, &request.FieldError{
+				Field:   "body",
+				Message: fmt.Sprintf("must be at most %d bytes", tooLarge.Limit),
+			})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to read request"})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		endpoint := r.Method + " " + r.URL.Path
+		tenant := tenantID(r.Context())
+
+		// Claim the key; if it's already taken, replay or reject
+		now := time.Now().UTC()
+		claimed, err := cfg.idempotency.CreateIdempotencyKey(r.Context(), database.CreateIdempotencyKeyParams{
+			TenantID:    tenant,
+			Key:         key,
+			Endpoint:    endpoint,
+			RequestHash: hash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(idempotencyKeyTTL),
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to store idempotency key"})
+			return
+		}
+		if claimed == 0 {
+			cfg.replayIdempotentResponse(w, r, tenant, key, endpoint, hash, next)
+			return
+		}
+
+		// A panicking handler releases the key too, or every retry would
+		// be told the request is still in progress until the key expires
+		params := database.DeleteIdempotencyKeyParams{TenantID: tenant, Key: key, Endpoint: endpoint}
+		defer func() {
+			if p := recover(); p != nil {
+				if err := cfg.idempotency.DeleteIdempotencyKey(context.Background(), params); err != nil {
+					log.Printf("failed to release idempotency key %q: %v", key, err)
+				}
+				panic(p)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next(rec, r)
+
+		// Server errors aren't stored, so the client can retry with the same key
+		if rec.status == 0 || rec.status >= 500 {
+			err = cfg.idempotency.DeleteIdempotencyKey(context.Background(), params)
+		} else {
+			err = cfg.idempotency.SaveIdempotentResponse(context.Background(), database.SaveIdempotentResponseParams{
+				TenantID:     tenant,
+				Key:          key,
+				Endpoint:     endpoint,
+				StatusCode:   int32(rec.status),
+				ContentType:  rec.Header().Get("Content-Type"),
+				ResponseBody: rec.body.Bytes(),
+			})
+		}
+		if err != nil {
+			log.Printf("failed to save idempotent response for key %q: %v", key, err)
+		}
+	}
+}
+
+// replayIdempotentResponse writes the stored response for a key that was
+// already used
+func (cfg *apiConfig) replayIdempotentResponse(w http.ResponseWriter, r *http.Request, tenant uuid.UUID, key, endpoint, hash string, next http.HandlerFunc) {
+	stored, err := cfg.idempotency.GetIdempotencyKey(r.Context(), database.GetIdempotencyKeyParams{
+		TenantID: tenant,
+		Key:      key,
+		Endpoint: endpoint,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// The first request failed and released the key in the meantime.
+		// Claim it again, so concurrent retries still run the handler once.
+		cfg.middlewareIdempotency(next)(w, r)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to load idempotency key"})
+		return
+	}
+
+	// An expired key is forgotten and the request is handled as new
+	if time.Now().UTC().After(stored.ExpiresAt) {
+		err = cfg.idempotency.DeleteIdempotencyKey(r.Context(), database.DeleteIdempotencyKeyParams{TenantID: tenant, Key: key, Endpoint: endpoint})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to release idempotency key"})
+			return
+		}
+		cfg.middlewareIdempotency(next)(w, r)
+		return
+	}
+
+	if stored.RequestHash != hash {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Idempotency key was already used for a different request"})
+		return
+	}
+	if stored.StatusCode == 0 {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(errorResponse{Error: "A request with this idempotency key is still in progress"})
+		return
+	}
+
+	if stored.ContentType != "" {
+		w.Header().Set("Content-Type", stored.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(int(stored.StatusCode))
+	w.Write(stored.ResponseBody)
+}
+
+// purgeIdempotencyKeys deletes expired idempotency keys once per
+// idempotencyPurgeEvery until ctx is done
+func (cfg *apiConfig) purgeIdempotencyKeys(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyPurgeEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := cfg.db.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC()); err != nil {
+			log.Printf("failed to purge idempotency keys: %v", err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// memoryKeys keeps idempotency keys in a map, as Postgres would
+type memoryKeys struct {
+	mu   sync.Mutex
+	keys map[[3]string]database.IdempotencyKey
+	// beforeGet, if set, runs once before the next lookup
+	beforeGet func()
+}
+
+func (m *memoryKeys) CreateIdempotencyKey(ctx context.Context, arg database.CreateIdempotencyKeyParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := [3]string{arg.TenantID.String(), arg.Key, arg.Endpoint}
+	if _, ok := m.keys[id]; ok {
+		return 0, nil
+	}
+	m.keys[id] = database.IdempotencyKey{
+		TenantID:    arg.TenantID,
+		Key:         arg.Key,
+		Endpoint:    arg.Endpoint,
+		RequestHash: arg.RequestHash,
+		CreatedAt:   arg.CreatedAt,
+		ExpiresAt:   arg.ExpiresAt,
+	}
+	return 1, nil
+}
+
+func (m *memoryKeys) GetIdempotencyKey(ctx context.Context, arg database.GetIdempotencyKeyParams) (database.IdempotencyKey, error) {
+	m.mu.Lock()
+	before := m.beforeGet
+	m.beforeGet = nil
+	m.mu.Unlock()
+	if before != nil {
+		before()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.keys[[3]string{arg.TenantID.String(), arg.Key, arg.Endpoint}]
+	if !ok {
+		return database.IdempotencyKey{}, sql.ErrNoRows
+	}
+	return stored, nil
+}
+
+func (m *memoryKeys) SaveIdempotentResponse(ctx context.Context, arg database.SaveIdempotentResponseParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := [3]string{arg.TenantID.String(), arg.Key, arg.Endpoint}
+	stored := m.keys[id]
+	stored.StatusCode = arg.StatusCode
+	stored.ContentType = arg.ContentType
+	stored.ResponseBody = arg.ResponseBody
+	m.keys[id] = stored
+	return nil
+}
+
+func (m *memoryKeys) DeleteIdempotencyKey(ctx context.Context, arg database.DeleteIdempotencyKeyParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, [3]string{arg.TenantID.String(), arg.Key, arg.Endpoint})
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	keys := &memoryKeys{keys: map[[3]string]database.IdempotencyKey{}}
+	cfg := &apiConfig{idempotency: keys}
+	calls := 0
+	handler := cfg.middlewareIdempotency(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/panic":
+			if calls == 1 {
+				panic("boom")
+			}
+			w.WriteHeader(http.StatusCreated)
+		case "/slow":
+			// A request for the same key arrives while this one runs
+			again := httptest.NewRequest(http.MethodPost, r.URL.Path, strings.NewReader("{}"))
+			again.Header.Set("Idempotency-Key", r.Header.Get("Idempotency-Key"))
+			rec := httptest.NewRecorder()
+			cfg.middlewareIdempotency(func(http.ResponseWriter, *http.Request) {
+				t.Error("a concurrent request with the same key ran")
+			})(rec, again)
+			if rec.Code != http.StatusConflict {
+				t.Errorf("concurrent request: status = %d, want 409", rec.Code)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"n":1}`))
+		}
+	})
+	do := func(path, key, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		calls = 0
+		first := do("/ok", "replay", "{}")
+		second := do("/ok", "replay", "{}")
+		if calls != 1 {
+			t.Errorf("handler ran %d times, want 1", calls)
+		}
+		if second.Code != first.Code || second.Body.String() != first.Body.String() {
+			t.Errorf("replayed %d %q, want %d %q", second.Code, second.Body, first.Code, first.Body)
+		}
+		if second.Header().Get("Idempotent-Replayed") != "true" || second.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("replayed headers = %v", second.Header())
+		}
+	})
+
+	t.Run("keys are per tenant", func(t *testing.T) {
+		calls = 0
+		do("/ok", "tenant", "{}")
+		req := httptest.NewRequest(http.MethodPost, "/ok", strings.NewReader("{}"))
+		req.Header.Set("Idempotency-Key", "tenant")
+		req = req.WithContext(context.WithValue(req.Context(), tenantKey{}, database.Tenant{ID: uuid.New()}))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if calls != 2 || rec.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("handler ran %d times, want 2: another tenant's response was replayed", calls)
+		}
+	})
+
+	t.Run("different body", func(t *testing.T) {
+		do("/ok", "mismatch", `{"a":1}`)
+		if rec := do("/ok", "mismatch", `{"a":2}`); rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want 422", rec.Code)
+		}
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		do("/slow", "conflict", "{}")
+	})
+
+	t.Run("server error releases the key", func(t *testing.T) {
+		calls = 0
+		do("/fail", "fail", "{}")
+		do("/fail", "fail", "{}")
+		if calls != 2 {
+			t.Errorf("handler ran %d times, want 2", calls)
+		}
+	})
+
+	t.Run("retry after the first attempt released the key", func(t *testing.T) {
+		// A retry finds the key taken, then the first attempt fails and
+		// releases it before the retry looks it up. The retry must claim
+		// the key, so the retry arriving while it runs gets a 409.
+		id := database.DeleteIdempotencyKeyParams{TenantID: tenantID(context.Background()), Key: "released", Endpoint: "POST /slow"}
+		keys.CreateIdempotencyKey(context.Background(), database.CreateIdempotencyKeyParams{
+			TenantID: id.TenantID,
+			Key:      id.Key,
+			Endpoint: id.Endpoint,
+		})
+		keys.beforeGet = func() { keys.DeleteIdempotencyKey(context.Background(), id) }
+
+		calls = 0
+		if rec := do("/slow", "released", "{}"); rec.Code != http.StatusCreated {
+			t.Errorf("retry status = %d, want 201", rec.Code)
+		}
+		if calls != 1 {
+			t.Errorf("handler ran %d times, want 1", calls)
+		}
+	})
+
+	t.Run("panic releases the key", func(t *testing.T) {
+		calls = 0
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("the panic was swallowed")
+				}
+			}()
+			do("/panic", "panic", "{}")
+		}()
+		if rec := do("/panic", "panic", "{}"); rec.Code != http.StatusCreated {
+			t.Errorf("retry status = %d, want 201", rec.Code)
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		rec := do("/ok", "large", strings.Repeat("a", request.DefaultMaxBodyBytes+1))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
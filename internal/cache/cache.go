@@ -0,0 +1,138 @@
+// Package cache has the caches used in front of hot reads: a size-bounded,
+// in-process LRU with expiry, and a Redis cache that instances share.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache holds values by key for a while. Misses are not errors: a cache
+// that can't be reached just misses.
+type Cache[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Set(key K, value V)
+	Delete(key K)
+	Purge()
+	Stats() Stats
+	ResetStats()
+}
+
+var _ Cache[string, int] = (*LRU[string, int])(nil)
+
+// Stats are the counters for one cache
+type Stats struct {
+	Name   string
+	Hits   int64
+	Misses int64
+	Len    int // -1 if the cache can't tell cheaply
+}
+
+// entry is a cached value and when it stops being served
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// LRU holds up to size values for ttl each, evicting the least recently
+// used when full. It is safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	name string
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu     sync.Mutex
+	ll     *list.List // front is most recently used
+	items  map[K]*list.Element
+	hits   int64
+	misses int64
+}
+
+// New returns an empty LRU named name, for its stats
+func New[K comparable, V any](name string, size int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		name:  name,
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		ll:    list.New(),
+		items: map[K]*list.Element{},
+	}
+}
+
+// Get returns the value for key if it is cached and hasn't expired
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		if c.now().Before(e.expires) {
+			c.ll.MoveToFront(el)
+			c.hits++
+			return e.value, true
+		}
+		c.remove(el)
+	}
+	c.misses++
+	var zero V
+	return zero, false
+}
+
+// Set caches value for key, replacing any value already there
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	if c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+// Delete removes key from the cache
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Purge removes everything from the cache
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = map[K]*list.Element{}
+}
+
+// Stats returns the cache's hit and miss counts and current length
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{Name: c.name, Hits: c.hits, Misses: c.misses, Len: c.ll.Len()}
+}
+
+// ResetStats zeroes the hit and miss counts
+func (c *LRU[K, V]) ResetStats() {
+	c.mu.Lock()
+	c.hits, c.misses = 0, 0
+	c.mu.Unlock()
+}
+
+// remove drops an element. Callers must hold mu.
+func (c *LRU[K, V]) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
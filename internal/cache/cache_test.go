@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Now()
+	c := New[string, int]("test", 2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %d, %v, want 1, true", v, ok)
+	}
+
+	// b is now the least recently used, so it goes first
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("b was not evicted")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("c is missing")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("a did not expire")
+	}
+
+	c.Set("d", 4)
+	c.Delete("d")
+	if _, ok := c.Get("d"); ok {
+		t.Error("d was not deleted")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 3 {
+		t.Errorf("hits, misses = %d, %d, want 2, 3", stats.Hits, stats.Misses)
+	}
+	c.Purge()
+	if stats := c.Stats(); stats.Len != 0 {
+		t.Errorf("len after Purge = %d, want 0", stats.Len)
+	}
+}
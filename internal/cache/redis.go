@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis calls are bounded so a slow Redis costs a miss rather than holding
+// up the request. Purging scans every key, so it gets longer.
+const (
+	redisTimeout      = 100 * time.Millisecond
+	redisPurgeTimeout = 10 * time.Second
+)
+
+// Redis holds values as JSON in Redis for ttl each, so every instance sees
+// the same entries and invalidations. Keys are prefixed with the cache's
+// name. Redis errors are logged and treated as misses. It is safe for
+// concurrent use.
+type Redis[K comparable, V any] struct {
+	client *redis.Client
+	name   string
+	prefix string
+	ttl    time.Duration
+
+	hits    atomic.Int64
+	misses  atomic.Int64
+	failing atomic.Bool // the last call failed
+}
+
+var _ Cache[string, int] = (*Redis[string, int])(nil)
+
+// NewRedis returns a cache named name that keeps values in client
+func NewRedis[K comparable, V any](client *redis.Client, name string, ttl time.Duration) *Redis[K, V] {
+	return &Redis[K, V]{
+		client: client,
+		name:   name,
+		prefix: "chirpy:cache:" + name + ":",
+		ttl:    ttl,
+	}
+}
+
+// Get returns the value for key if it is cached
+func (c *Redis[K, V]) Get(key K) (V, bool) {
+	var value V
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		c.check(nil)
+		c.misses.Add(1)
+		return value, false
+	}
+	if c.check(err); err != nil {
+		c.misses.Add(1)
+		return value, false
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		log.Printf("redis cache %s: bad value for %v: %v", c.name, key, err)
+		c.misses.Add(1)
+		return value, false
+	}
+	c.hits.Add(1)
+	return value, true
+}
+
+// Set caches value for key, replacing any value already there
+func (c *Redis[K, V]) Set(key K, value V) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("redis cache %s: encoding %v: %v", c.name, key, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	c.check(c.client.Set(ctx, c.key(key), data, c.ttl).Err())
+}
+
+// Delete removes key from the cache
+func (c *Redis[K, V]) Delete(key K) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	c.check(c.client.Del(ctx, c.key(key)).Err())
+}
+
+// Purge removes every key of this cache
+func (c *Redis[K, V]) Purge() {
+	ctx, cancel := context.WithTimeout(context.Background(), redisPurgeTimeout)
+	defer cancel()
+
+	// Keys are collected before deleting any, so the scan doesn't skip
+	// keys that move as others are removed
+	var keys []string
+	iter := c.client.Scan(ctx, 0, c.prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		c.check(err)
+		return
+	}
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), 1000)]
+		if err := c.client.Del(ctx, batch...).Err(); err != nil {
+			c.check(err)
+			return
+		}
+		keys = keys[len(batch):]
+	}
+	c.check(nil)
+}
+
+// Stats returns this instance's hit and miss counts. The number of entries
+// isn't counted, as that would mean scanning Redis.
+func (c *Redis[K, V]) Stats() Stats {
+	return Stats{Name: c.name, Hits: c.hits.Load(), Misses: c.misses.Load(), Len: -1}
+}
+
+// ResetStats zeroes the hit and miss counts
+func (c *Redis[K, V]) ResetStats() {
+	c.hits.Store(0)
+	c.misses.Store(0)
+}
+
+// key returns the Redis key for key
+func (c *Redis[K, V]) key(key K) string {
+	return c.prefix + fmt.Sprint(key)
+}
+
+// check logs when Redis starts failing and when it recovers, rather than
+// every failed call
+func (c *Redis[K, V]) check(err error) {
+	if err != nil {
+		if !c.failing.Swap(true) {
+			log.Printf("redis cache %s: %v; reading through to the store", c.name, err)
+		}
+		return
+	}
+	if c.failing.Swap(false) {
+		log.Printf("redis cache %s: reachable again", c.name)
+	}
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type point struct {
+	X, Y int
+}
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+func TestRedis(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := NewRedis[string, point](client, "points", time.Minute)
+
+	c.Set("a", point{1, 2})
+	if v, ok := c.Get("a"); !ok || v != (point{1, 2}) {
+		t.Fatalf("Get(a) = %v, %v, want {1 2}, true", v, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) hit, want a miss")
+	}
+
+	// Another instance sees the same entries and invalidations
+	other := NewRedis[string, point](client, "points", time.Minute)
+	if _, ok := other.Get("a"); !ok {
+		t.Error("other instance missed a")
+	}
+	other.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("a is still cached after another instance deleted it")
+	}
+
+	// Entries expire
+	c.Set("a", point{1, 2})
+	mr.FastForward(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("a is still cached after its TTL")
+	}
+
+	if got := c.Stats(); got.Hits != 1 || got.Misses != 3 || got.Len != -1 {
+		t.Errorf("Stats() = %+v, want 1 hit, 3 misses, len -1", got)
+	}
+	c.ResetStats()
+	if got := c.Stats(); got.Hits != 0 || got.Misses != 0 {
+		t.Errorf("after ResetStats, Stats() = %+v", got)
+	}
+}
+
+func TestRedisPurge(t *testing.T) {
+	mr, client := newTestRedis(t)
+	points := NewRedis[int, point](client, "points", time.Minute)
+	counts := NewRedis[int, int64](client, "counts", time.Minute)
+	for i := 0; i < 2500; i++ {
+		points.Set(i, point{i, i})
+	}
+	counts.Set(1, 7)
+
+	points.Purge()
+	if n := len(mr.Keys()); n != 1 {
+		t.Errorf("%d keys left, want only the other cache's", n)
+	}
+	if v, ok := counts.Get(1); !ok || v != 7 {
+		t.Errorf("counts.Get(1) = %d, %v, want 7, true", v, ok)
+	}
+}
+
+func TestRedisDownIsAMiss(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := NewRedis[string, int](client, "numbers", time.Minute)
+	c.Set("a", 1)
+	mr.Close()
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) hit with Redis down")
+	}
+	c.Set("b", 2)
+	c.Delete("a")
+	c.Purge()
+	if got := c.Stats(); got.Misses != 1 {
+		t.Errorf("misses = %d, want 1", got.Misses)
+	}
+}
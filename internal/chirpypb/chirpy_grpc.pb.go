@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: chirpy.proto
+
+package chirpypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ChirpyService_CreateUser_FullMethodName     = "/chirpy.v1.ChirpyService/CreateUser"
+	ChirpyService_GetUser_FullMethodName        = "/chirpy.v1.ChirpyService/GetUser"
+	ChirpyService_CreateChirp_FullMethodName    = "/chirpy.v1.ChirpyService/CreateChirp"
+	ChirpyService_GetChirp_FullMethodName       = "/chirpy.v1.ChirpyService/GetChirp"
+	ChirpyService_BatchGetChirps_FullMethodName = "/chirpy.v1.ChirpyService/BatchGetChirps"
+)
+
+// ChirpyServiceClient is the client API for ChirpyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChirpyService exposes the core user and chirp operations to internal
+// services. It shares the HTTP API's store and rules, and is authorized
+// with the same admin tokens. The tenant is picked by the x-chirpy-tenant
+// metadata key, like the X-Chirpy-Tenant header.
+type ChirpyServiceClient interface {
+	// CreateUser creates a user in the request's tenant
+	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error)
+	// GetUser returns a user of the request's tenant
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// CreateChirp validates, cleans and stores a chirp as POST /api/chirps does
+	CreateChirp(ctx context.Context, in *CreateChirpRequest, opts ...grpc.CallOption) (*CreateChirpResponse, error)
+	// GetChirp returns a chirp unless it is held for review
+	GetChirp(ctx context.Context, in *GetChirpRequest, opts ...grpc.CallOption) (*Chirp, error)
+	// BatchGetChirps looks up many chirps by ID at once, as
+	// POST /api/chirps/batch does
+	BatchGetChirps(ctx context.Context, in *BatchGetChirpsRequest, opts ...grpc.CallOption) (*BatchGetChirpsResponse, error)
+}
+
+type chirpyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChirpyServiceClient(cc grpc.ClientConnInterface) ChirpyServiceClient {
+	return &chirpyServiceClient{cc}
+}
+
+func (c *chirpyServiceClient) CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, ChirpyService_CreateUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chirpyServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, ChirpyService_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chirpyServiceClient) CreateChirp(ctx context.Context, in *CreateChirpRequest, opts ...grpc.CallOption) (*CreateChirpResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateChirpResponse)
+	err := c.cc.Invoke(ctx, ChirpyService_CreateChirp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chirpyServiceClient) GetChirp(ctx context.Context, in *GetChirpRequest, opts ...grpc.CallOption) (*Chirp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Chirp)
+	err := c.cc.Invoke(ctx, ChirpyService_GetChirp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chirpyServiceClient) BatchGetChirps(ctx context.Context, in *BatchGetChirpsRequest, opts ...grpc.CallOption) (*BatchGetChirpsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetChirpsResponse)
+	err := c.cc.Invoke(ctx, ChirpyService_BatchGetChirps_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChirpyServiceServer is the server API for ChirpyService service.
+// All implementations must embed UnimplementedChirpyServiceServer
+// for forward compatibility.
+//
+// ChirpyService exposes the core user and chirp operations to internal
+// services. It shares the HTTP API's store and rules, and is authorized
+// with the same admin tokens. The tenant is picked by the x-chirpy-tenant
+// metadata key, like the X-Chirpy-Tenant header.
+type ChirpyServiceServer interface {
+	// CreateUser creates a user in the request's tenant
+	CreateUser(context.Context, *CreateUserRequest) (*User, error)
+	// GetUser returns a user of the request's tenant
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// CreateChirp validates, cleans and stores a chirp as POST /api/chirps does
+	CreateChirp(context.Context, *CreateChirpRequest) (*CreateChirpResponse, error)
+	// GetChirp returns a chirp unless it is held for review
+	GetChirp(context.Context, *GetChirpRequest) (*Chirp, error)
+	// BatchGetChirps looks up many chirps by ID at once, as
+	// POST /api/chirps/batch does
+	BatchGetChirps(context.Context, *BatchGetChirpsRequest) (*BatchGetChirpsResponse, error)
+	mustEmbedUnimplementedChirpyServiceServer()
+}
+
+// UnimplementedChirpyServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChirpyServiceServer struct{}
+
+func (UnimplementedChirpyServiceServer) CreateUser(context.Context, *CreateUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedChirpyServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedChirpyServiceServer) CreateChirp(context.Context, *CreateChirpRequest) (*CreateChirpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateChirp not implemented")
+}
+func (UnimplementedChirpyServiceServer) GetChirp(context.Context, *GetChirpRequest) (*Chirp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChirp not implemented")
+}
+func (UnimplementedChirpyServiceServer) BatchGetChirps(context.Context, *BatchGetChirpsRequest) (*BatchGetChirpsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetChirps not implemented")
+}
+func (UnimplementedChirpyServiceServer) mustEmbedUnimplementedChirpyServiceServer() {}
+func (UnimplementedChirpyServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeChirpyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChirpyServiceServer will
+// result in compilation errors.
+type UnsafeChirpyServiceServer interface {
+	mustEmbedUnimplementedChirpyServiceServer()
+}
+
+func RegisterChirpyServiceServer(s grpc.ServiceRegistrar, srv ChirpyServiceServer) {
+	// If the following call pancis, it indicates UnimplementedChirpyServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChirpyService_ServiceDesc, srv)
+}
+
+func _ChirpyService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpyServiceServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpyService_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpyServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChirpyService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpyServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpyService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpyServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChirpyService_CreateChirp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateChirpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpyServiceServer).CreateChirp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpyService_CreateChirp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpyServiceServer).CreateChirp(ctx, req.(*CreateChirpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChirpyService_GetChirp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChirpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpyServiceServer).GetChirp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpyService_GetChirp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpyServiceServer).GetChirp(ctx, req.(*GetChirpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChirpyService_BatchGetChirps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetChirpsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpyServiceServer).BatchGetChirps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpyService_BatchGetChirps_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpyServiceServer).BatchGetChirps(ctx, req.(*BatchGetChirpsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChirpyService_ServiceDesc is the grpc.ServiceDesc for ChirpyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChirpyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "chirpy.v1.ChirpyService",
+	HandlerType: (*ChirpyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateUser",
+			Handler:    _ChirpyService_CreateUser_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _ChirpyService_GetUser_Handler,
+		},
+		{
+			MethodName: "CreateChirp",
+			Handler:    _ChirpyService_CreateChirp_Handler,
+		},
+		{
+			MethodName: "GetChirp",
+			Handler:    _ChirpyService_GetChirp_Handler,
+		},
+		{
+			MethodName: "BatchGetChirps",
+			Handler:    _ChirpyService_BatchGetChirps_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "chirpy.proto",
+}
@@ -0,0 +1,5 @@
+// Package chirpypb holds the protobuf messages and gRPC service generated
+// from chirpy.proto
+package chirpypb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative chirpy.proto
@@ -0,0 +1,430 @@
+// Package config loads and validates the server settings from the
+// environment (and an optional .env file) at startup.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Platforms the server can run as
+const (
+	PlatformDev  = "dev"
+	PlatformProd = "prod"
+	// PlatformDemo runs without Postgres, keeping data in memory
+	PlatformDemo = "demo"
+)
+
+// Database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// JSON field naming conventions
+const (
+	JSONCaseSnake = "snake"
+	JSONCaseCamel = "camel"
+)
+
+// Config holds every server setting
+type Config struct {
+	// DBDriver is "postgres" or "sqlite" (DB_DRIVER, default postgres)
+	DBDriver string
+	// DBURL is the Postgres connection string, or the SQLite database file
+	// (DB_URL, required unless demo)
+	DBURL string
+	// Platform is "dev", "prod" or "demo" (PLATFORM, default prod)
+	Platform string
+	// Port is the plain HTTP port (PORT, default 8080)
+	Port string
+	// GRPCAddr is the listen address of the gRPC API, such as ":9090"
+	// (GRPC_ADDR); the gRPC API is off when empty
+	GRPCAddr string
+	// JobWorkers is the number of background job workers (JOB_WORKERS, default 4)
+	JobWorkers int
+	// ShutdownTimeout bounds graceful shutdown (SHUTDOWN_TIMEOUT, default 30s)
+	ShutdownTimeout time.Duration
+	// CursorSecret signs pagination cursors (CURSOR_SECRET, random per
+	// process if unset)
+	CursorSecret string
+	// JSONCase is the default field naming of JSON responses, "snake" or
+	// "camel" (JSON_CASE, default snake); clients can ask for either
+	JSONCase string
+	// AdminTokens maps admin names to the tokens that open the admin
+	// endpoints (ADMIN_TOKENS, comma-separated name:token pairs). Without
+	// any, the admin endpoints are only open in dev and demo mode.
+	AdminTokens map[string]string
+
+	EmailGateway EmailGateway
+	TLS          TLS
+	Timeouts     Timeouts
+	Strikes      Strikes
+	Reports      Reports
+	Moderation   Moderation
+	Web          Web
+	Cache        Cache
+	Tracing      Tracing
+}
+
+// EmailGateway configures posting chirps by email
+type EmailGateway struct {
+	// Domain of the secret posting addresses (EMAIL_GATEWAY_DOMAIN)
+	Domain string
+	// WebhookSecret authenticates the email provider (EMAIL_WEBHOOK_SECRET)
+	WebhookSecret string
+}
+
+// Enabled reports whether posting by email is configured
+func (e EmailGateway) Enabled() bool {
+	return e.Domain != "" && e.WebhookSecret != ""
+}
+
+// TLS configures HTTPS
+type TLS struct {
+	CertFile         string   // TLS_CERT_FILE
+	KeyFile          string   // TLS_KEY_FILE
+	AutocertDomains  []string // AUTOCERT_DOMAINS, comma-separated
+	AutocertCacheDir string   // AUTOCERT_CACHE_DIR, default "certs"
+	AutocertEmail    string   // AUTOCERT_EMAIL
+	HTTPSAddr        string   // HTTPS_ADDR, default ":443"
+	HSTSMaxAge       int      // HSTS_MAX_AGE in seconds, default two years
+	// HSTSIncludeSubdomains extends HSTS to every subdomain. It is off by
+	// default, since it breaks subdomains that don't serve HTTPS.
+	HSTSIncludeSubdomains bool // HSTS_INCLUDE_SUBDOMAINS
+}
+
+// Enabled reports whether the server should serve HTTPS
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || len(t.AutocertDomains) > 0
+}
+
+// Timeouts bound how long connections and requests may take
+type Timeouts struct {
+	Read    time.Duration // READ_TIMEOUT, default 10s
+	Write   time.Duration // WRITE_TIMEOUT, default 30s
+	Idle    time.Duration // IDLE_TIMEOUT, default 2m
+	Request time.Duration // REQUEST_TIMEOUT for handler contexts, default 15s
+}
+
+// Strikes configures penalties for content violations
+type Strikes struct {
+	Decay      time.Duration // STRIKE_DECAY, how long a strike counts, default 30 days
+	WarnAt     int           // STRIKE_WARN_AT, default 1
+	CooldownAt int           // STRIKE_COOLDOWN_AT, default 3
+	Cooldown   time.Duration // STRIKE_COOLDOWN after the latest strike, default 1h
+	SuspendAt  int           // STRIKE_SUSPEND_AT, default 5
+}
+
+// Reports configures content reporting
+type Reports struct {
+	HideThreshold int // REPORT_HIDE_THRESHOLD, client addresses whose open reports hide a chirp, default 3
+}
+
+// Moderation configures the external moderation classifier
+type Moderation struct {
+	URL       string        // MODERATION_URL, the classifier webhook
+	Secret    string        // MODERATION_SECRET, sent as a bearer token
+	Threshold float64       // MODERATION_THRESHOLD, scores at or above are held, default 0.8
+	Timeout   time.Duration // MODERATION_TIMEOUT per attempt, default 2s
+	FailOpen  bool          // MODERATION_FAIL_OPEN, publish when the classifier is down, default true
+}
+
+// Enabled reports whether chirps are sent to a classifier
+func (m Moderation) Enabled() bool {
+	return m.URL != ""
+}
+
+// Web configures the static files served under /app
+type Web struct {
+	Root        string        // WEB_ROOT, a directory to serve instead of the embedded client
+	CacheMaxAge time.Duration // WEB_CACHE_MAX_AGE for assets other than HTML, default 1h
+}
+
+// Cache configures the cache of chirps and rechirp counts
+type Cache struct {
+	Size     int           // CACHE_SIZE, entries per in-process cache, 0 to disable, default 10000
+	ChirpTTL time.Duration // CACHE_CHIRP_TTL, default 5m
+	CountTTL time.Duration // CACHE_COUNT_TTL for rechirp counts, default 30s
+	RedisURL string        // REDIS_URL, keeps the cache in Redis instead of in process
+}
+
+// Tracing configures exporting OpenTelemetry traces. The exporter and SDK
+// read the other standard OTEL_* variables themselves.
+type Tracing struct {
+	// Endpoint is the OTLP/HTTP collector (OTEL_EXPORTER_OTLP_TRACES_ENDPOINT,
+	// else OTEL_EXPORTER_OTLP_ENDPOINT); tracing is off if neither is set
+	Endpoint    string
+	ServiceName string // OTEL_SERVICE_NAME, default "chirpy"
+}
+
+// Enabled reports whether traces are exported
+func (t Tracing) Enabled() bool {
+	return t.Endpoint != ""
+}
+
+// Addr returns the plain HTTP listen address
+func (c Config) Addr() string {
+	return ":" + c.Port
+}
+
+// Load reads .env if present, then builds and validates the Config from the
+// environment. All problems are reported together.
+func Load() (Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, fmt.Errorf("loading .env: %w", err)
+	}
+
+	var errs []error
+	cfg := Config{
+		DBDriver:        getString("DB_DRIVER", DriverPostgres),
+		DBURL:           os.Getenv("DB_URL"),
+		Platform:        getString("PLATFORM", PlatformProd),
+		Port:            getString("PORT", "8080"),
+		GRPCAddr:        os.Getenv("GRPC_ADDR"),
+		JobWorkers:      getInt("JOB_WORKERS", 4, &errs),
+		ShutdownTimeout: getDuration("SHUTDOWN_TIMEOUT", 30*time.Second, &errs),
+		CursorSecret:    os.Getenv("CURSOR_SECRET"),
+		JSONCase:        getString("JSON_CASE", JSONCaseSnake),
+		AdminTokens:     getAdminTokens("ADMIN_TOKENS", &errs),
+		EmailGateway: EmailGateway{
+			Domain:        os.Getenv("EMAIL_GATEWAY_DOMAIN"),
+			WebhookSecret: os.Getenv("EMAIL_WEBHOOK_SECRET"),
+		},
+		TLS: TLS{
+			CertFile:         os.Getenv("TLS_CERT_FILE"),
+			KeyFile:          os.Getenv("TLS_KEY_FILE"),
+			AutocertDomains:  getList("AUTOCERT_DOMAINS"),
+			AutocertCacheDir: getString("AUTOCERT_CACHE_DIR", "certs"),
+			AutocertEmail:    os.Getenv("AUTOCERT_EMAIL"),
+			HTTPSAddr:        getString("HTTPS_ADDR", ":443"),
+			HSTSMaxAge:       getInt("HSTS_MAX_AGE", 63072000, &errs),
+
+			HSTSIncludeSubdomains: getBool("HSTS_INCLUDE_SUBDOMAINS", false, &errs),
+		},
+		Timeouts: Timeouts{
+			Read:    getDuration("READ_TIMEOUT", 10*time.Second, &errs),
+			Write:   getDuration("WRITE_TIMEOUT", 30*time.Second, &errs),
+			Idle:    getDuration("IDLE_TIMEOUT", 2*time.Minute, &errs),
+			Request: getDuration("REQUEST_TIMEOUT", 15*time.Second, &errs),
+		},
+		Strikes: Strikes{
+			Decay:      getDuration("STRIKE_DECAY", 30*24*time.Hour, &errs),
+			WarnAt:     getInt("STRIKE_WARN_AT", 1, &errs),
+			CooldownAt: getInt("STRIKE_COOLDOWN_AT", 3, &errs),
+			Cooldown:   getDuration("STRIKE_COOLDOWN", time.Hour, &errs),
+			SuspendAt:  getInt("STRIKE_SUSPEND_AT", 5, &errs),
+		},
+		Reports: Reports{
+			HideThreshold: getInt("REPORT_HIDE_THRESHOLD", 3, &errs),
+		},
+		Moderation: Moderation{
+			URL:       getString("MODERATION_URL", ""),
+			Secret:    getString("MODERATION_SECRET", ""),
+			Threshold: getFloat("MODERATION_THRESHOLD", 0.8, &errs),
+			Timeout:   getDuration("MODERATION_TIMEOUT", 2*time.Second, &errs),
+			FailOpen:  getBool("MODERATION_FAIL_OPEN", true, &errs),
+		},
+		Web: Web{
+			Root:        getString("WEB_ROOT", ""),
+			CacheMaxAge: getDuration("WEB_CACHE_MAX_AGE", time.Hour, &errs),
+		},
+		Cache: Cache{
+			Size:     getInt("CACHE_SIZE", 10000, &errs),
+			ChirpTTL: getDuration("CACHE_CHIRP_TTL", 5*time.Minute, &errs),
+			CountTTL: getDuration("CACHE_COUNT_TTL", 30*time.Second, &errs),
+			RedisURL: os.Getenv("REDIS_URL"),
+		},
+		Tracing: Tracing{
+			Endpoint:    getString("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")),
+			ServiceName: getString("OTEL_SERVICE_NAME", "chirpy"),
+		},
+	}
+
+	if cfg.DBURL == "" && cfg.Platform != PlatformDemo {
+		errs = append(errs, errors.New("DB_URL is required"))
+	}
+	if cfg.DBDriver != DriverPostgres && cfg.DBDriver != DriverSQLite {
+		errs = append(errs, fmt.Errorf("DB_DRIVER must be %q or %q, got %q", DriverPostgres, DriverSQLite, cfg.DBDriver))
+	}
+	if cfg.Platform != PlatformDev && cfg.Platform != PlatformProd && cfg.Platform != PlatformDemo {
+		errs = append(errs, fmt.Errorf("PLATFORM must be %q, %q or %q, got %q", PlatformDev, PlatformProd, PlatformDemo, cfg.Platform))
+	}
+	if cfg.JSONCase != JSONCaseSnake && cfg.JSONCase != JSONCaseCamel {
+		errs = append(errs, fmt.Errorf("JSON_CASE must be %q or %q, got %q", JSONCaseSnake, JSONCaseCamel, cfg.JSONCase))
+	}
+	if _, err := strconv.ParseUint(cfg.Port, 10, 16); err != nil {
+		errs = append(errs, fmt.Errorf("PORT must be a port number, got %q", cfg.Port))
+	}
+	if _, _, err := net.SplitHostPort(cfg.GRPCAddr); cfg.GRPCAddr != "" && err != nil {
+		errs = append(errs, fmt.Errorf("GRPC_ADDR must be a host:port address, got %q", cfg.GRPCAddr))
+	}
+	if cfg.JobWorkers < 1 {
+		errs = append(errs, errors.New("JOB_WORKERS must be at least 1"))
+	}
+	if cfg.CursorSecret != "" && len(cfg.CursorSecret) < 32 {
+		errs = append(errs, errors.New("CURSOR_SECRET must be at least 32 characters"))
+	}
+	if (cfg.EmailGateway.Domain == "") != (cfg.EmailGateway.WebhookSecret == "") {
+		errs = append(errs, errors.New("EMAIL_GATEWAY_DOMAIN and EMAIL_WEBHOOK_SECRET must be set together"))
+	}
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if cfg.TLS.CertFile != "" && len(cfg.TLS.AutocertDomains) > 0 {
+		errs = append(errs, errors.New("set either TLS_CERT_FILE/TLS_KEY_FILE or AUTOCERT_DOMAINS, not both"))
+	}
+
+	if cfg.Timeouts.Read <= 0 || cfg.Timeouts.Write <= 0 || cfg.Timeouts.Idle <= 0 || cfg.Timeouts.Request <= 0 {
+		errs = append(errs, errors.New("READ_TIMEOUT, WRITE_TIMEOUT, IDLE_TIMEOUT and REQUEST_TIMEOUT must be positive"))
+	}
+	if cfg.Timeouts.Request >= cfg.Timeouts.Write {
+		errs = append(errs, errors.New("REQUEST_TIMEOUT must be shorter than WRITE_TIMEOUT so timed-out requests can still respond"))
+	}
+	if cfg.Strikes.Decay <= 0 || cfg.Strikes.Cooldown <= 0 {
+		errs = append(errs, errors.New("STRIKE_DECAY and STRIKE_COOLDOWN must be positive"))
+	}
+	if st := cfg.Strikes; st.WarnAt < 1 || st.CooldownAt < st.WarnAt || st.SuspendAt < st.CooldownAt {
+		errs = append(errs, errors.New("strike thresholds must satisfy 1 <= STRIKE_WARN_AT <= STRIKE_COOLDOWN_AT <= STRIKE_SUSPEND_AT"))
+	}
+	if cfg.Reports.HideThreshold < 1 {
+		errs = append(errs, errors.New("REPORT_HIDE_THRESHOLD must be at least 1"))
+	}
+	if cfg.Moderation.Threshold <= 0 || cfg.Moderation.Threshold > 1 {
+		errs = append(errs, errors.New("MODERATION_THRESHOLD must be above 0 and at most 1"))
+	}
+	if cfg.Moderation.Timeout <= 0 {
+		errs = append(errs, errors.New("MODERATION_TIMEOUT must be positive"))
+	}
+	if cfg.Web.CacheMaxAge < 0 {
+		errs = append(errs, errors.New("WEB_CACHE_MAX_AGE must not be negative"))
+	}
+	if cfg.Cache.Size < 0 {
+		errs = append(errs, errors.New("CACHE_SIZE must not be negative"))
+	}
+	if cfg.Cache.Size > 0 && (cfg.Cache.ChirpTTL <= 0 || cfg.Cache.CountTTL <= 0) {
+		errs = append(errs, errors.New("CACHE_CHIRP_TTL and CACHE_COUNT_TTL must be positive"))
+	}
+	if cfg.Cache.RedisURL != "" {
+		if u, err := url.Parse(cfg.Cache.RedisURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") {
+			errs = append(errs, errors.New("REDIS_URL must be a redis:// or rediss:// URL"))
+		}
+	}
+	if cfg.Tracing.Enabled() {
+		if u, err := url.Parse(cfg.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT must be an http or https URL, got %q", cfg.Tracing.Endpoint))
+		}
+	}
+
+	return cfg, errors.Join(errs...)
+}
+
+// getString returns the variable, or def if it is unset or empty
+func getString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getInt parses the variable as an integer, or returns def if it is unset
+func getInt(key string, def int, errs *[]error) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s must be an integer, got %q", key, v))
+		return def
+	}
+	return n
+}
+
+// getFloat parses the variable as a number, or returns def if it is unset
+func getFloat(key string, def float64, errs *[]error) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s must be a number, got %q", key, v))
+		return def
+	}
+	return f
+}
+
+// getBool parses the variable as a boolean, or returns def if it is unset
+func getBool(key string, def bool, errs *[]error) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s must be true or false, got %q", key, v))
+		return def
+	}
+	return b
+}
+
+// getDuration parses the variable as a Go duration such as "30s", or
+// returns def if it is unset
+func getDuration(key string, def time.Duration, errs *[]error) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s must be a duration like 30s, got %q", key, v))
+		return def
+	}
+	return d
+}
+
+// minAdminTokenLength keeps admin tokens too long to guess
+const minAdminTokenLength = 16
+
+// getAdminTokens parses a comma-separated list of name:token pairs
+func getAdminTokens(key string, errs *[]error) map[string]string {
+	tokens := map[string]string{}
+	for _, pair := range getList(key) {
+		name, token, ok := strings.Cut(pair, ":")
+		name = strings.TrimSpace(name)
+		switch {
+		case !ok || name == "":
+			*errs = append(*errs, fmt.Errorf("%s must be comma-separated name:token pairs", key))
+		case len(token) < minAdminTokenLength:
+			*errs = append(*errs, fmt.Errorf("%s: the token for %q must be at least %d characters", key, name, minAdminTokenLength))
+		case tokens[name] != "":
+			*errs = append(*errs, fmt.Errorf("%s: %q is listed twice", key, name))
+		default:
+			tokens[name] = token
+			continue
+		}
+		return nil
+	}
+	return tokens
+}
+
+// getList splits a comma-separated variable, dropping empty entries
+func getList(key string) []string {
+	var out []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package cursor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type position struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	codec := New([]byte("secret"))
+	want := position{
+		CreatedAt: time.Date(2025, 3, 14, 9, 26, 53, 589793000, time.UTC),
+		ID:        "5b2c1f0e-8a43-4a3f-9d8e-2f6b7c1d0a9e",
+	}
+
+	token, err := codec.Encode(want)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var got position
+	if err := codec.Decode(token, &got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("Decode = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeRejectsInvalidTokens(t *testing.T) {
+	codec := New([]byte("secret"))
+	token, err := codec.Encode(position{ID: "a"})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	payload, sig, _ := strings.Cut(token, ".")
+
+	forged, err := New([]byte("other")).Encode(position{ID: "b"})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", payload},
+		{"bad base64", "!!!." + sig},
+		{"signed with another key", forged},
+		{"payload swapped", forgedPayload + "." + sig},
+		{"signature truncated", payload + "." + sig[:len(sig)-2]},
+		{"not json", "bm90IGpzb24." + sig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got position
+			if err := codec.Decode(tt.token, &got); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Decode(%q) error = %v, want ErrInvalid", tt.token, err)
+			}
+		})
+	}
+}
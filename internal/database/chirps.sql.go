@@ -10,12 +10,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at, body, user_id
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, tenant_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, body, user_id, tenant_id
 `
 
 type CreateChirpParams struct {
@@ -24,6 +25,7 @@ type CreateChirpParams struct {
 	UpdatedAt time.Time
 	Body      string
 	UserID    uuid.UUID
+	TenantID  uuid.UUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
@@ -33,6 +35,7 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		arg.UpdatedAt,
 		arg.Body,
 		arg.UserID,
+		arg.TenantID,
 	)
 	var i Chirp
 	err := row.Scan(
@@ -41,6 +44,98 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getChirp = `-- name: GetChirp :one
+SELECT id, created_at, updated_at, body, user_id, tenant_id FROM chirps
+WHERE id = $1 AND tenant_id = $2
+`
+
+type GetChirpParams struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+}
+
+func (q *Queries) GetChirp(ctx context.Context, arg GetChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirp, arg.ID, arg.TenantID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, tenant_id FROM chirps
+WHERE tenant_id = $1 AND id = ANY($2::uuid[])
+`
+
+type GetChirpsByIDsParams struct {
+	TenantID uuid.UUID
+	Ids      []uuid.UUID
+}
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, arg.TenantID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentDuplicateChirp = `-- name: GetRecentDuplicateChirp :one
+SELECT id, created_at, updated_at, body, user_id, tenant_id FROM chirps
+WHERE user_id = $1 AND body = $2 AND created_at >= $3
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetRecentDuplicateChirpParams struct {
+	UserID    uuid.UUID
+	Body      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetRecentDuplicateChirp(ctx context.Context, arg GetRecentDuplicateChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getRecentDuplicateChirp, arg.UserID, arg.Body, arg.CreatedAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.TenantID,
 	)
 	return i, err
 }
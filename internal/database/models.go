@@ -5,22 +5,172 @@
 package database
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AuditLog struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Actor     string
+	Action    string
+	Target    string
+	RequestID string
+	Details   string
+	TenantID  uuid.UUID
+}
+
 type Chirp struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Body      string
 	UserID    uuid.UUID
+	TenantID  uuid.UUID
+}
+
+type ChirpAuthor struct {
+	ChirpID    uuid.UUID
+	UserID     uuid.UUID
+	Email      string
+	CapturedAt time.Time
+}
+
+type ChirpDailyView struct {
+	ChirpID uuid.UUID
+	Day     time.Time
+	Views   int64
+}
+
+type ChirpHold struct {
+	ChirpID   uuid.UUID
+	Reason    string
+	CreatedAt time.Time
+	Details   string
+}
+
+type ChirpView struct {
+	ChirpID uuid.UUID
+	Viewer  string
+	Day     time.Time
+	Counted bool
+}
+
+type ChirpViewCount struct {
+	ChirpID   uuid.UUID
+	ViewCount int64
+}
+
+type IdempotencyKey struct {
+	Key          string
+	Endpoint     string
+	RequestHash  string
+	StatusCode   int32
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	TenantID     uuid.UUID
+}
+
+type InstanceRule struct {
+	Position    int32
+	Title       string
+	Description string
+	TenantID    uuid.UUID
+}
+
+type Job struct {
+	ID          uuid.UUID
+	Kind        string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	RunAt       time.Time
+	LastError   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	TenantID    uuid.UUID
+}
+
+type LinkPreview struct {
+	Url         string
+	Title       string
+	Description string
+	ImageUrl    string
+	FetchedAt   time.Time
+}
+
+type Poll struct {
+	ChirpID   uuid.UUID
+	Options   []string
+	ClosesAt  time.Time
+	CreatedAt time.Time
+}
+
+type PollVote struct {
+	ChirpID     uuid.UUID
+	OptionIndex int32
+	CreatedAt   time.Time
+	Voter       string
+}
+
+type Rechirp struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Report struct {
+	ID           uuid.UUID
+	ChirpID      uuid.UUID
+	ReporterID   uuid.UUID
+	Reason       string
+	Details      string
+	Status       string
+	CreatedAt    time.Time
+	ReviewedAt   sql.NullTime
+	ReporterAddr string
+}
+
+type ReportReason struct {
+	Code     string
+	Label    string
+	Position int32
+	TenantID uuid.UUID
+}
+
+type Tenant struct {
+	ID        uuid.UUID
+	Slug      string
+	Name      string
+	Host      sql.NullString
+	CreatedAt time.Time
 }
 
 type User struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Email         string
+	PostingSecret string
+	TenantID      uuid.UUID
+}
+
+type UserActivity struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type UserStrike struct {
 	ID        uuid.UUID
+	UserID    uuid.UUID
+	Reason    string
+	Details   string
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	Email     string
+	ExpiresAt time.Time
 }
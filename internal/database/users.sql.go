@@ -13,16 +13,18 @@ import (
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email)
-VALUES ($1, $2, $3, $4)
-RETURNING id, created_at, updated_at, email
+INSERT INTO users (id, created_at, updated_at, email, posting_secret, tenant_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, email, posting_secret, tenant_id
 `
 
 type CreateUserParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Email     string
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Email         string
+	PostingSecret string
+	TenantID      uuid.UUID
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -31,6 +33,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.Email,
+		arg.PostingSecret,
+		arg.TenantID,
 	)
 	var i User
 	err := row.Scan(
@@ -38,15 +42,88 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Email,
+		&i.PostingSecret,
+		&i.TenantID,
 	)
 	return i, err
 }
 
 const deleteAllUsers = `-- name: DeleteAllUsers :exec
 DELETE FROM users
+WHERE tenant_id = $1
 `
 
-func (q *Queries) DeleteAllUsers(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteAllUsers)
+func (q *Queries) DeleteAllUsers(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteAllUsers, tenantID)
 	return err
 }
+
+const getUser = `-- name: GetUser :one
+SELECT id, created_at, updated_at, email, posting_secret, tenant_id FROM users
+WHERE id = $1 AND tenant_id = $2
+`
+
+type GetUserParams struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+}
+
+func (q *Queries) GetUser(ctx context.Context, arg GetUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, arg.ID, arg.TenantID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.PostingSecret,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getUserByPostingSecret = `-- name: GetUserByPostingSecret :one
+SELECT id, created_at, updated_at, email, posting_secret, tenant_id FROM users
+WHERE posting_secret = $1
+`
+
+func (q *Queries) GetUserByPostingSecret(ctx context.Context, postingSecret string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByPostingSecret, postingSecret)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.PostingSecret,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const updatePostingSecret = `-- name: UpdatePostingSecret :one
+UPDATE users
+SET posting_secret = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, posting_secret, tenant_id
+`
+
+type UpdatePostingSecretParams struct {
+	ID            uuid.UUID
+	PostingSecret string
+	UpdatedAt     time.Time
+}
+
+func (q *Queries) UpdatePostingSecret(ctx context.Context, arg UpdatePostingSecretParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updatePostingSecret, arg.ID, arg.PostingSecret, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.PostingSecret,
+		&i.TenantID,
+	)
+	return i, err
+}
@@ -0,0 +1,253 @@
+// Package jobs runs background work from a Postgres-backed queue with retries.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/retry"
+	"github.com/hydeh3r3/chirpy/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Job statuses as stored in the jobs table
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// Queue tuning
+const (
+	pollInterval     = time.Second
+	staleAfter       = 15 * time.Minute
+	keepDoneFor      = 7 * 24 * time.Hour
+	maintenanceEvery = time.Hour
+)
+
+// Policy is how failed jobs are retried: 5 attempts, backing off from 5s
+// up to an hour
+var Policy = retry.Policy{
+	Name:        "jobs",
+	MaxAttempts: 5,
+	BaseDelay:   5 * time.Second,
+	MaxDelay:    time.Hour,
+	Multiplier:  2,
+	Jitter:      0.2,
+}
+
+// Handler processes a job's JSON payload. Returning an error schedules a
+// retry, unless it is wrapped with retry.Permanent.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Queue enqueues jobs and runs them on a pool of workers
+type Queue struct {
+	db       *database.Queries
+	workers  int
+	handlers map[string]Handler
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New returns a Queue that runs jobs on the given number of workers
+func New(db *database.Queries, workers int) *Queue {
+	return &Queue{
+		db:       db,
+		workers:  workers,
+		handlers: map[string]Handler{},
+		stop:     make(chan struct{}),
+	}
+}
+
+// Register sets the handler for a kind of job. Call before Start.
+func (q *Queue) Register(kind string, h Handler) {
+	q.handlers[kind] = h
+}
+
+// Enqueue adds a job for a tenant to run as soon as a worker is free
+func (q *Queue) Enqueue(ctx context.Context, tenantID uuid.UUID, kind string, payload any) error {
+	return q.EnqueueAt(ctx, tenantID, kind, payload, time.Now().UTC())
+}
+
+// EnqueueAt adds a job for a tenant that won't run before runAt. Workers run
+// every tenant's jobs; the tenant decides whose admins see it.
+func (q *Queue) EnqueueAt(ctx context.Context, tenantID uuid.UUID, kind string, payload any, runAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding %s payload: %w", kind, err)
+	}
+	_, err = q.db.EnqueueJob(ctx, database.EnqueueJobParams{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Payload:     data,
+		MaxAttempts: int32(Policy.MaxAttempts),
+		RunAt:       runAt.UTC(),
+		CreatedAt:   time.Now().UTC(),
+		TenantID:    tenantID,
+	})
+	return err
+}
+
+// Start launches the workers and the maintenance loop
+func (q *Queue) Start() {
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	q.wg.Add(1)
+	go q.maintain()
+}
+
+// Shutdown stops claiming new jobs and waits for running ones to finish, or
+// for ctx to be done, whichever comes first
+func (q *Queue) Shutdown(ctx context.Context) error {
+	close(q.stop)
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work claims and runs jobs until the queue is stopped
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		ran, err := q.runNext(context.Background())
+		if err != nil {
+			log.Printf("job queue: %v", err)
+		}
+		if ran {
+			continue
+		}
+
+		// Nothing to do, wait before polling again
+		select {
+		case <-q.stop:
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// runNext claims and runs a single job, reporting whether there was one
+func (q *Queue) runNext(ctx context.Context) (bool, error) {
+	job, err := q.db.ClaimJob(ctx, time.Now().UTC())
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claiming job: %w", err)
+	}
+
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		return true, q.db.FailJob(ctx, database.FailJobParams{
+			ID:        job.ID,
+			LastError: fmt.Sprintf("no handler registered for %q", job.Kind),
+			UpdatedAt: time.Now().UTC(),
+		})
+	}
+
+	// Trace the run but not the claim, which happens on every poll
+	retry.RecordAttempt(Policy.Name)
+	runCtx, span := tracing.Start(ctx, "job "+job.Kind, trace.WithAttributes(
+		attribute.String("chirpy.job_id", job.ID.String()),
+		attribute.Int("chirpy.job_attempt", int(job.Attempts)),
+	))
+	runErr := handler(runCtx, job.Payload)
+	tracing.End(span, runErr)
+	now := time.Now().UTC()
+	switch {
+	case runErr == nil:
+		err = q.db.CompleteJob(ctx, database.CompleteJobParams{ID: job.ID, UpdatedAt: now})
+	case job.Attempts >= job.MaxAttempts || retry.IsPermanent(runErr):
+		retry.RecordGiveUp(Policy.Name)
+		log.Printf("job %s (%s) failed permanently: %v", job.ID, job.Kind, runErr)
+		err = q.db.FailJob(ctx, database.FailJobParams{
+			ID:        job.ID,
+			LastError: runErr.Error(),
+			UpdatedAt: now,
+		})
+	default:
+		retry.RecordRetry(Policy.Name)
+		err = q.db.RetryJob(ctx, database.RetryJobParams{
+			ID:        job.ID,
+			RunAt:     now.Add(Policy.Delay(int(job.Attempts))),
+			LastError: runErr.Error(),
+			UpdatedAt: now,
+		})
+	}
+	if err != nil {
+		return true, fmt.Errorf("updating job %s: %w", job.ID, err)
+	}
+	return true, nil
+}
+
+// PurgeDone deletes jobs that finished more than a week before now and
+// returns how many it deleted
+func PurgeDone(ctx context.Context, db *database.Queries, now time.Time) (int64, error) {
+	return db.PurgeDoneJobs(ctx, now.Add(-keepDoneFor))
+}
+
+// PurgeViews deletes the per-client rows that deduplicate chirp views once
+// their UTC day is over and they have been rolled up, and returns how many
+// it deleted. Views only dedupe within a day, and the daily counts keep
+// what author analytics need.
+func PurgeViews(ctx context.Context, db *database.Queries, now time.Time) (int64, error) {
+	return db.PurgeChirpViews(ctx, now.UTC().Truncate(24*time.Hour))
+}
+
+// maintain periodically requeues jobs stuck in running (e.g. after a crash)
+// and purges old finished jobs and chirp view rows
+func (q *Queue) maintain() {
+	defer q.wg.Done()
+	for {
+		ctx := context.Background()
+		now := time.Now().UTC()
+		if n, err := q.db.RequeueStaleJobs(ctx, database.RequeueStaleJobsParams{
+			UpdatedAt:   now,
+			UpdatedAt_2: now.Add(-staleAfter),
+		}); err != nil {
+			log.Printf("job queue: requeueing stale jobs: %v", err)
+		} else if n > 0 {
+			log.Printf("job queue: requeued %d stale jobs", n)
+		}
+		if _, err := PurgeDone(ctx, q.db, now); err != nil {
+			log.Printf("job queue: purging done jobs: %v", err)
+		}
+		if _, err := PurgeViews(ctx, q.db, now); err != nil {
+			log.Printf("job queue: purging chirp views: %v", err)
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-time.After(maintenanceEvery):
+		}
+	}
+}
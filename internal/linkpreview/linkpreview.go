@@ -0,0 +1,248 @@
+// Package linkpreview fetches Open Graph metadata for links found in chirps.
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Limits applied to every preview fetch
+const (
+	fetchTimeout = 5 * time.Second
+	maxBodySize  = 512 << 10
+	maxRedirects = 3
+)
+
+// ErrBlockedAddress is returned when a link resolves to an address we refuse to contact
+var ErrBlockedAddress = errors.New("linkpreview: blocked address")
+
+// Preview holds the metadata extracted from a linked page
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+}
+
+// Fetcher downloads pages and extracts preview metadata
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher returns a Fetcher whose client only talks to public addresses
+func NewFetcher() *Fetcher {
+	dialer := &net.Dialer{
+		Timeout: fetchTimeout,
+		Control: blockPrivateAddresses,
+	}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   fetchTimeout,
+		ResponseHeaderTimeout: fetchTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &Fetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   fetchTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("linkpreview: stopped after %d redirects", maxRedirects)
+				}
+				return checkURL(req.URL)
+			},
+		},
+	}
+}
+
+// blockPrivateAddresses rejects connections to loopback, private and other
+// internal addresses, after DNS resolution so rebinding can't get around it
+func blockPrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if port != "80" && port != "443" {
+		return ErrBlockedAddress
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// specialPurpose lists the IANA special-purpose and other ranges that
+// aren't globally reachable, or that tunnel to addresses that might not be
+// (NAT64, 6to4, Teredo). IPv4-mapped addresses are checked as IPv4.
+var specialPurpose = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.31.196.0/24"), // AS112
+	netip.MustParsePrefix("192.52.193.0/24"), // AMT
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("192.175.48.0/24"), // AS112
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved and broadcast
+	netip.MustParsePrefix("::/96"),           // unspecified, loopback and IPv4-compatible
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local NAT64
+	netip.MustParsePrefix("100::/64"),        // discard
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, incl. Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+	netip.MustParsePrefix("3fff::/20"),       // documentation
+	netip.MustParsePrefix("5f00::/16"),       // segment routing
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("fec0::/10"),       // site-local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address
+func isPublicIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range specialPurpose {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkURL only allows plain http(s) URLs without credentials
+func checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("linkpreview: unsupported scheme %q", u.Scheme)
+	}
+	if u.User != nil || u.Hostname() == "" {
+		return fmt.Errorf("linkpreview: invalid url %q", u.String())
+	}
+	return nil
+}
+
+// Fetch downloads rawURL and extracts its Open Graph title, description and image
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Preview, error) {
+	preview := Preview{URL: rawURL}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return preview, err
+	}
+	if err := checkURL(u); err != nil {
+		return preview, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return preview, err
+	}
+	req.Header.Set("User-Agent", "Chirpy-LinkPreview/1.0")
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return preview, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return preview, fmt.Errorf("linkpreview: unexpected status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" {
+		return preview, fmt.Errorf("linkpreview: unsupported content type %q", mediaType)
+	}
+
+	parsePreview(io.LimitReader(resp.Body, maxBodySize), resp.Request.URL, &preview)
+	return preview, nil
+}
+
+// parsePreview reads meta tags from the document head into preview
+func parsePreview(r io.Reader, base *url.URL, preview *Preview) {
+	var title string
+	tokens := html.NewTokenizer(r)
+	for {
+		switch tokens.Next() {
+		case html.ErrorToken:
+			if preview.Title == "" {
+				preview.Title = strings.TrimSpace(title)
+			}
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokens.TagName()
+			switch string(name) {
+			case "title":
+				if tokens.Next() == html.TextToken {
+					title = string(tokens.Text())
+				}
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+				var property, content string
+				for {
+					key, val, more := tokens.TagAttr()
+					switch string(key) {
+					case "property", "name":
+						property = strings.ToLower(string(val))
+					case "content":
+						content = strings.TrimSpace(string(val))
+					}
+					if !more {
+						break
+					}
+				}
+				switch property {
+				case "og:title":
+					preview.Title = content
+				case "og:description":
+					preview.Description = content
+				case "description":
+					if preview.Description == "" {
+						preview.Description = content
+					}
+				case "og:image":
+					if img, err := base.Parse(content); err == nil && checkURL(img) == nil {
+						preview.ImageURL = img.String()
+					}
+				}
+			}
+		case html.EndTagToken:
+			if name, _ := tokens.TagName(); string(name) == "head" {
+				if preview.Title == "" {
+					preview.Title = strings.TrimSpace(title)
+				}
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Package metrics counts requests and errors per route and method, and
+// writes them in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RouteCounts are the request metrics for one route and method
+type RouteCounts struct {
+	Route        string
+	Method       string
+	Requests     int64
+	ClientErrors int64 // 4xx responses
+	ServerErrors int64 // 5xx responses
+}
+
+// routeKey identifies a route and method
+type routeKey struct {
+	route  string
+	method string
+}
+
+// Registry holds the request counters for one server
+type Registry struct {
+	mu     sync.Mutex
+	counts map[routeKey]*RouteCounts
+}
+
+// New returns an empty Registry
+func New() *Registry {
+	return &Registry{counts: map[routeKey]*RouteCounts{}}
+}
+
+// Record counts a request to route (the mux pattern, not the raw path, to
+// keep the number of series bounded) answered with status
+func (r *Registry) Record(route, method string, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := routeKey{route: route, method: method}
+	c, ok := r.counts[key]
+	if !ok {
+		c = &RouteCounts{Route: route, Method: method}
+		r.counts[key] = c
+	}
+	c.Requests++
+	switch {
+	case status >= 500:
+		c.ServerErrors++
+	case status >= 400:
+		c.ClientErrors++
+	}
+}
+
+// Snapshot returns the counters sorted by route and method
+func (r *Registry) Snapshot() []RouteCounts {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]RouteCounts, 0, len(r.counts))
+	for _, c := range r.counts {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Route != out[j].Route {
+			return out[i].Route < out[j].Route
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// Requests returns how many requests route has served, over all methods
+func (r *Registry) Requests(route string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for key, c := range r.counts {
+		if key.route == route {
+			n += c.Requests
+		}
+	}
+	return n
+}
+
+// Reset clears all counters
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	r.counts = map[routeKey]*RouteCounts{}
+	r.mu.Unlock()
+}
+
+// labelEscaper escapes a label value for the text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Counter is one labelled counter value for WriteCounter
+type Counter struct {
+	Labels []string // name, value pairs
+	Value  int64
+}
+
+// WritePrometheus writes the request counters in the Prometheus text format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	snapshot := r.Snapshot()
+	var requests, errs []Counter
+	for _, c := range snapshot {
+		labels := []string{"route", c.Route, "method", c.Method}
+		requests = append(requests, Counter{Labels: labels, Value: c.Requests})
+		errs = append(errs,
+			Counter{Labels: append(labels[:4:4], "class", "4xx"), Value: c.ClientErrors},
+			Counter{Labels: append(labels[:4:4], "class", "5xx"), Value: c.ServerErrors},
+		)
+	}
+
+	if err := WriteCounter(w, "chirpy_http_requests_total", "Requests served, by route and method.", requests); err != nil {
+		return err
+	}
+	return WriteCounter(w, "chirpy_http_request_errors_total", "Error responses, by route, method and status class.", errs)
+}
+
+// WriteCounter writes a counter family in the Prometheus text format
+func WriteCounter(w io.Writer, name, help string, counters []Counter) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name); err != nil {
+		return err
+	}
+	for _, c := range counters {
+		pairs := make([]string, 0, len(c.Labels)/2)
+		for i := 0; i+1 < len(c.Labels); i += 2 {
+			pairs = append(pairs, c.Labels[i]+`="`+labelEscaper.Replace(c.Labels[i+1])+`"`)
+		}
+		if _, err := fmt.Fprintf(w, "%s{%s} %d\n", name, strings.Join(pairs, ","), c.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
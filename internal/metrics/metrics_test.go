@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	r := New()
+	r.Record("/api/chirps", "POST", 201)
+	r.Record("/api/chirps", "POST", 400)
+	r.Record("/api/chirps", "POST", 500)
+	r.Record(`/odd"route`, "GET", 200)
+
+	var b strings.Builder
+	if err := r.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE chirpy_http_requests_total counter\n",
+		`chirpy_http_requests_total{route="/api/chirps",method="POST"} 3` + "\n",
+		`chirpy_http_request_errors_total{route="/api/chirps",method="POST",class="4xx"} 1` + "\n",
+		`chirpy_http_request_errors_total{route="/api/chirps",method="POST",class="5xx"} 1` + "\n",
+		`chirpy_http_requests_total{route="/odd\"route",method="GET"} 1` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, b.String())
+		}
+	}
+
+	if got := r.Requests("/api/chirps"); got != 3 {
+		t.Errorf("Requests = %d, want 3", got)
+	}
+	r.Reset()
+	if got := r.Snapshot(); len(got) != 0 {
+		t.Errorf("Snapshot after Reset = %v, want empty", got)
+	}
+}
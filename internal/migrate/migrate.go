@@ -0,0 +1,210 @@
+// Package migrate checks and applies the goose migrations in sql/schema.
+// It reads and writes goose's own history table, so it can be used
+// alongside the goose CLI, but only understands what the schema uses: an
+// Up and a Down section per file, each run in a transaction.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Entry is a row of goose's migration history
+type Entry struct {
+	Version int64
+	Applied bool
+}
+
+// Versions returns the goose versions of the migrations in fsys, taken from
+// the number at the start of each file name
+func Versions(fsys fs.FS) ([]int64, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int64, 0, len(names))
+	for _, name := range names {
+		version, err := fileVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+// fileVersion returns the version at the start of a migration file name
+func fileVersion(name string) (int64, error) {
+	prefix, _, _ := strings.Cut(name, "_")
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("migration %s: no version number", name)
+	}
+	return version, nil
+}
+
+// Pending returns the versions in want that history doesn't leave applied.
+// History is in the order goose wrote it, so a later rollback undoes an
+// earlier apply.
+func Pending(want []int64, history []Entry) []int64 {
+	applied := map[int64]bool{}
+	for _, row := range history {
+		applied[row.Version] = row.Applied
+	}
+	var pending []int64
+	for _, version := range want {
+		if !applied[version] {
+			pending = append(pending, version)
+		}
+	}
+	return pending
+}
+
+// History returns goose's migration history from db, oldest first
+func History(ctx context.Context, db *sql.DB) ([]Entry, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version_id, is_applied FROM goose_db_version ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []Entry
+	for rows.Next() {
+		var row Entry
+		if err := rows.Scan(&row.Version, &row.Applied); err != nil {
+			return nil, err
+		}
+		history = append(history, row)
+	}
+	return history, rows.Err()
+}
+
+// upSection returns the statements between "-- +goose Up" and
+// "-- +goose Down"
+func upSection(src string) (string, error) {
+	var up strings.Builder
+	inUp := false
+	for _, line := range strings.SplitAfter(src, "\n") {
+		annotation, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +goose ")
+		if !ok {
+			if inUp {
+				up.WriteString(line)
+			}
+			continue
+		}
+		switch strings.TrimSpace(annotation) {
+		case "Up":
+			inUp = true
+		case "Down":
+			inUp = false
+		case "StatementBegin", "StatementEnd":
+			// Postgres runs the section as one multi-statement query anyway
+		default:
+			return "", fmt.Errorf("unsupported goose annotation %q", annotation)
+		}
+	}
+	if up.Len() == 0 {
+		return "", fmt.Errorf("no -- +goose Up section")
+	}
+	return up.String(), nil
+}
+
+// Up applies every pending migration in fsys to the Postgres database db,
+// oldest first, each in its own transaction, and returns the versions it
+// applied. goose's history table is created if it doesn't exist.
+func Up(ctx context.Context, db *sql.DB, fsys fs.FS) ([]int64, error) {
+	if err := ensureHistory(ctx, db); err != nil {
+		return nil, err
+	}
+	history, err := History(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	files := map[int64]string{}
+	var want []int64
+	for _, name := range names {
+		version, err := fileVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		files[version] = name
+		want = append(want, version)
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+
+	var applied []int64
+	for _, version := range Pending(want, history) {
+		if err := apply(ctx, db, fsys, files[version], version); err != nil {
+			return applied, fmt.Errorf("migration %s: %w", files[version], err)
+		}
+		applied = append(applied, version)
+	}
+	return applied, nil
+}
+
+// ensureHistory creates goose's history table as goose would
+func ensureHistory(ctx context.Context, db *sql.DB) error {
+	var exists bool
+	err := db.QueryRowContext(ctx, `SELECT to_regclass('goose_db_version') IS NOT NULL`).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `CREATE TABLE goose_db_version (
+		id serial NOT NULL,
+		version_id bigint NOT NULL,
+		is_applied boolean NOT NULL,
+		tstamp timestamp NULL DEFAULT now(),
+		PRIMARY KEY (id)
+	)`)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, true)`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// apply runs one migration's Up section and records it
+func apply(ctx context.Context, db *sql.DB, fsys fs.FS, name string, version int64) error {
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	up, err := upSection(string(src))
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, up); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)`, version)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,63 @@
+package migrate
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+
+	schema "github.com/hydeh3r3/chirpy/sql"
+)
+
+func TestPending(t *testing.T) {
+	want := []int64{1, 2, 3}
+	history := []Entry{
+		{Version: 0, Applied: true},
+		{Version: 1, Applied: true},
+		{Version: 2, Applied: true},
+		{Version: 3, Applied: true},
+		{Version: 3, Applied: false}, // rolled back
+	}
+	if got := Pending(want, history); !reflect.DeepEqual(got, []int64{3}) {
+		t.Errorf("pending = %v, want [3]", got)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	versions, err := Versions(schema.Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i := 1; i < len(versions); i++ {
+		if versions[i] <= versions[i-1] {
+			t.Errorf("versions not increasing: %v", versions)
+		}
+	}
+}
+
+func TestUpSection(t *testing.T) {
+	src := "-- +goose Up\nCREATE TABLE a (id INT);\n\n-- +goose Down\nDROP TABLE a;\n"
+	got, err := upSection(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "CREATE TABLE a (id INT);\n\n"; got != want {
+		t.Errorf("up = %q, want %q", got, want)
+	}
+
+	if _, err := upSection("-- +goose NO TRANSACTION\n-- +goose Up\nSELECT 1;\n"); err == nil {
+		t.Error("NO TRANSACTION was accepted")
+	}
+
+	// Every embedded migration can be applied
+	fsys := schema.Schema()
+	names, _ := fs.Glob(fsys, "*.sql")
+	for _, name := range names {
+		src, _ := fs.ReadFile(fsys, name)
+		if _, err := upSection(string(src)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
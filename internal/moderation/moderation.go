@@ -0,0 +1,115 @@
+// Package moderation sends chirps to an external classifier webhook and
+// reports how likely they are to break the content rules.
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/retry"
+	"github.com/hydeh3r3/chirpy/internal/tracing"
+)
+
+// maxResponseSize caps how much of a classifier response is read
+const maxResponseSize = 64 << 10
+
+// Policy retries classifier calls that fail with a network error or a 5xx
+var Policy = retry.Policy{
+	Name:        "moderation",
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    time.Second,
+	Multiplier:  2,
+	Jitter:      0.2,
+}
+
+// Verdict is the classifier's answer for one chirp
+type Verdict struct {
+	// Score is from 0 (fine) to 1 (certainly breaks the rules)
+	Score float64 `json:"score"`
+	// Categories name what was found, such as "hate" or "spam"
+	Categories []string `json:"categories"`
+}
+
+// request is the JSON body sent to the classifier
+type request struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Classifier calls a moderation webhook
+type Classifier struct {
+	url     string
+	secret  string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// New returns a Classifier for the webhook at url. secret, if set, is sent
+// as a bearer token, and timeout bounds each attempt.
+func New(url, secret string, timeout time.Duration) *Classifier {
+	return &Classifier{
+		url:     url,
+		secret:  secret,
+		timeout: timeout,
+		client:  &http.Client{Transport: tracing.Transport(nil)},
+	}
+}
+
+// Classify sends a chirp's text to the webhook, retrying with Policy
+func (c *Classifier) Classify(ctx context.Context, id, text string) (Verdict, error) {
+	body, err := json.Marshal(request{ID: id, Text: text})
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	var verdict Verdict
+	err = Policy.Do(ctx, func(ctx context.Context) error {
+		var err error
+		verdict, err = c.classify(ctx, body)
+		return err
+	})
+	return verdict, err
+}
+
+// classify makes a single attempt
+func (c *Classifier) classify(ctx context.Context, body []byte) (Verdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, retry.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.secret != "" {
+		req.Header.Set("Authorization", "Bearer "+c.secret)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return Verdict{}, fmt.Errorf("moderation: classifier returned %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, retry.Permanent(fmt.Errorf("moderation: classifier returned %s", resp.Status))
+	}
+
+	var verdict Verdict
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&verdict); err != nil {
+		return Verdict{}, retry.Permanent(fmt.Errorf("moderation: decoding verdict: %w", err))
+	}
+	if verdict.Score < 0 || verdict.Score > 1 {
+		return Verdict{}, retry.Permanent(fmt.Errorf("moderation: score %v is outside [0, 1]", verdict.Score))
+	}
+	return verdict, nil
+}
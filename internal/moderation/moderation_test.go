@@ -0,0 +1,59 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if got := r.Header.Get("Authorization"); got != "Bearer s3cret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req request
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ID != "chirp-1" || req.Text != "hello" {
+			t.Errorf("request = %+v", req)
+		}
+		// Fail once to exercise the retry
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"score":0.9,"categories":["spam"]}`))
+	}))
+	defer srv.Close()
+
+	verdict, err := New(srv.URL, "s3cret", time.Second).Classify(context.Background(), "chirp-1", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Score != 0.9 || len(verdict.Categories) != 1 || verdict.Categories[0] != "spam" {
+		t.Errorf("verdict = %+v", verdict)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestClassifyClientErrorIsNotRetried(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	if _, err := New(srv.URL, "", time.Second).Classify(context.Background(), "chirp-1", "hello"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,91 @@
+// Package partitions maintains the monthly range partitions of the chirps table.
+package partitions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// MonthsAhead is how many future months get a partition ahead of time
+const MonthsAhead = 3
+
+// partitionName returns the partition table name for the month containing t
+func partitionName(t time.Time) string {
+	return fmt.Sprintf("chirps_y%04dm%02d", t.Year(), int(t.Month()))
+}
+
+// monthStart truncates t to the first instant of its month in UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// EnsureChirpPartitions creates the partition for the current month and the
+// next MonthsAhead months if they don't exist yet
+func EnsureChirpPartitions(ctx context.Context, db *sql.DB, now time.Time) error {
+	start := monthStart(now)
+	for i := 0; i <= MonthsAhead; i++ {
+		from := start.AddDate(0, i, 0)
+		if err := ensurePartition(ctx, db, from, from.AddDate(0, 1, 0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensurePartition creates and attaches a single monthly partition, moving
+// any rows for that range out of the default partition first. The move is
+// marked so the chirps' ids, and the rows that reference them, stay.
+func ensurePartition(ctx context.Context, db *sql.DB, from, to time.Time) error {
+	name := partitionName(from)
+
+	var exists bool
+	err := db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("checking partition %s: %w", name, err)
+	}
+	if exists {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fromStr := from.Format("2006-01-02")
+	toStr := to.Format("2006-01-02")
+	stmts := []string{
+		`SET LOCAL chirpy.moving_chirps = on`,
+		fmt.Sprintf(`CREATE TABLE %s (LIKE chirps INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, name),
+		fmt.Sprintf(`INSERT INTO %s SELECT * FROM chirps_default WHERE created_at >= '%s' AND created_at < '%s'`, name, fromStr, toStr),
+		fmt.Sprintf(`DELETE FROM chirps_default WHERE created_at >= '%s' AND created_at < '%s'`, fromStr, toStr),
+		fmt.Sprintf(`ALTER TABLE chirps ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`, name, fromStr, toStr),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("creating partition %s: %w", name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Run ensures partitions immediately and then once per interval until ctx is done
+func Run(ctx context.Context, db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := EnsureChirpPartitions(ctx, db, time.Now()); err != nil {
+			log.Printf("partition maintenance failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
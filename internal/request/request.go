@@ -0,0 +1,159 @@
+// Package request parses and validates request parameters so that malformed
+// input is reported consistently as a 400 naming the offending field.
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultMaxBodyBytes is the body size limit used by most JSON endpoints
+const DefaultMaxBodyBytes = 1 << 20
+
+// FieldError describes a single invalid request field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ParseUUIDParam parses the named path parameter as a UUID
+func ParseUUIDParam(r *http.Request, name string) (uuid.UUID, error) {
+	raw := r.PathValue(name)
+	if raw == "" {
+		return uuid.Nil, &FieldError{Field: name, Message: "is required"}
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, &FieldError{Field: name, Message: "must be a valid UUID"}
+	}
+	return id, nil
+}
+
+// ParseUUIDQuery parses the named query parameter as a UUID, returning
+// uuid.Nil if it is absent
+func ParseUUIDQuery(r *http.Request, name string) (uuid.UUID, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, &FieldError{Field: name, Message: "must be a valid UUID"}
+	}
+	return id, nil
+}
+
+// ParseInt parses the named query parameter as an integer in [min, max],
+// returning def if it is absent
+func ParseInt(r *http.Request, name string, def, min, max int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, &FieldError{Field: name, Message: "must be an integer"}
+	}
+	if n < min || n > max {
+		return 0, &FieldError{Field: name, Message: fmt.Sprintf("must be between %d and %d", min, max)}
+	}
+	return n, nil
+}
+
+// ParseTime parses the named query parameter as an RFC 3339 timestamp,
+// returning the zero time if it is absent
+func ParseTime(r *http.Request, name string) (time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, &FieldError{Field: name, Message: "must be an RFC 3339 timestamp"}
+	}
+	return t, nil
+}
+
+// DecodeJSON decodes a JSON request body of at most maxBytes into dst
+func DecodeJSON(r *http.Request, dst any, maxBytes int64) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(body)) > maxBytes {
+		return &FieldError{Field: "body", Message: fmt.Sprintf("must be at most %d bytes", maxBytes)}
+	}
+
+	err = json.Unmarshal(body, dst)
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return &FieldError{Field: typeErr.Field, Message: "has the wrong type"}
+	}
+	if fieldErr := findInvalidField(body, dst); fieldErr != nil {
+		return fieldErr
+	}
+	return &FieldError{Field: "body", Message: "Invalid JSON"}
+}
+
+// findInvalidField finds the top-level field of a JSON object that failed to
+// decode into dst, a pointer to a struct. Errors from a field's
+// UnmarshalText, such as a malformed UUID, don't say which field they came
+// from, so each field is decoded on its own.
+func findInvalidField(body []byte, dst any) *FieldError {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return nil
+	}
+	t := v.Elem().Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		for key, raw := range fields {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			if json.Unmarshal(raw, reflect.New(field.Type).Interface()) != nil {
+				message := "is invalid"
+				if field.Type == reflect.TypeOf(uuid.UUID{}) {
+					message = "must be a valid UUID"
+				}
+				return &FieldError{Field: name, Message: message}
+			}
+		}
+	}
+	return nil
+}
+
+// RequireUUID reports a field error if id is the nil UUID
+func RequireUUID(field string, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &FieldError{Field: field, Message: "is required"}
+	}
+	return nil
+}
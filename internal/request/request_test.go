@@ -0,0 +1,48 @@
+package request
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDecodeJSONFieldErrors(t *testing.T) {
+	type payload struct {
+		UserID uuid.UUID   `json:"user_id"`
+		IDs    []uuid.UUID `json:"ids"`
+		Count  int         `json:"count"`
+	}
+	tests := []struct {
+		body    string
+		field   string
+		message string
+	}{
+		{`{"user_id": "not-a-uuid"}`, "user_id", "must be a valid UUID"},
+		{`{"ids": ["` + uuid.NewString() + `", "nope"]}`, "ids", "is invalid"},
+		{`{"count": "three"}`, "count", "has the wrong type"},
+		{`{"user_id": `, "body", "Invalid JSON"},
+		{`{"user_id": "` + strings.Repeat("a", 64) + `"}`, "body", "must be at most 64 bytes"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		var dst payload
+		err := DecodeJSON(r, &dst, 64)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) {
+			t.Errorf("%s: err = %v, want a FieldError", tt.body, err)
+			continue
+		}
+		if fieldErr.Field != tt.field || fieldErr.Message != tt.message {
+			t.Errorf("%s: got %s %q, want %s %q", tt.body, fieldErr.Field, fieldErr.Message, tt.field, tt.message)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"user_id": "`+uuid.Nil.String()+`", "count": 3}`))
+	var dst payload
+	if err := DecodeJSON(r, &dst, DefaultMaxBodyBytes); err != nil || dst.Count != 3 {
+		t.Errorf("valid body: err = %v, count = %d", err, dst.Count)
+	}
+}
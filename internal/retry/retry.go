@@ -0,0 +1,161 @@
+// Package retry provides shared retry policies with exponential backoff and
+// jitter, and counts retries per policy so they show up in metrics.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Policy describes how an operation is retried
+type Policy struct {
+	// Name identifies the policy in retry metrics
+	Name string
+	// MaxAttempts is the total number of tries, including the first
+	MaxAttempts int
+	// BaseDelay is the wait after the first failure
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between tries
+	MaxDelay time.Duration
+	// Multiplier grows the delay after each failure
+	Multiplier float64
+	// Jitter is the fraction of each delay that is randomized, from 0 to 1
+	Jitter float64
+}
+
+// Delay returns how long to wait after the given failed attempt (1-based)
+func (p Policy) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := float64(p.BaseDelay) * math.Pow(mult, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// permanentError marks an error that retrying won't fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so Do stops retrying and returns it immediately
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
+
+// Do calls fn until it succeeds, returns a permanent error, the policy runs
+// out of attempts, or ctx is done. It returns fn's last error.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		RecordAttempt(p.Name)
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if IsPermanent(err) || attempt >= p.MaxAttempts {
+			RecordGiveUp(p.Name)
+			return err
+		}
+
+		RecordRetry(p.Name)
+		timer := time.NewTimer(p.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			RecordGiveUp(p.Name)
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Counts are the retry metrics for one policy
+type Counts struct {
+	Name     string
+	Attempts int64
+	Retries  int64
+	GiveUps  int64
+}
+
+var (
+	mu     sync.Mutex
+	counts = map[string]*Counts{}
+)
+
+// entry returns the counters for a policy, creating them on first use.
+// Callers must hold mu.
+func entry(name string) *Counts {
+	c, ok := counts[name]
+	if !ok {
+		c = &Counts{Name: name}
+		counts[name] = c
+	}
+	return c
+}
+
+// RecordAttempt counts a try made under the named policy
+func RecordAttempt(name string) {
+	mu.Lock()
+	entry(name).Attempts++
+	mu.Unlock()
+}
+
+// RecordRetry counts a failed try that will be retried
+func RecordRetry(name string) {
+	mu.Lock()
+	entry(name).Retries++
+	mu.Unlock()
+}
+
+// RecordGiveUp counts an operation that failed for good
+func RecordGiveUp(name string) {
+	mu.Lock()
+	entry(name).GiveUps++
+	mu.Unlock()
+}
+
+// Stats returns a snapshot of the retry metrics, sorted by policy name
+func Stats() []Counts {
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]Counts, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Reset clears all retry metrics
+func Reset() {
+	mu.Lock()
+	counts = map[string]*Counts{}
+	mu.Unlock()
+}
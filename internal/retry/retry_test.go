@@ -0,0 +1,122 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	p := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	// A multiplier below 1 keeps the delay flat
+	flat := Policy{BaseDelay: time.Second, Multiplier: 0.5}
+	if got := flat.Delay(3); got != time.Second {
+		t.Errorf("flat Delay(3) = %v, want 1s", got)
+	}
+}
+
+func TestDelayJitter(t *testing.T) {
+	tests := []struct {
+		jitter   float64
+		min, max time.Duration
+	}{
+		{0, time.Second, time.Second},
+		{0.2, 800 * time.Millisecond, time.Second},
+		{1, 0, time.Second},
+	}
+	for _, tt := range tests {
+		p := Policy{BaseDelay: time.Second, Multiplier: 2, Jitter: tt.jitter}
+		for i := 0; i < 1000; i++ {
+			if got := p.Delay(1); got < tt.min || got > tt.max {
+				t.Fatalf("jitter %v: Delay(1) = %v, want within [%v, %v]", tt.jitter, got, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds first time", 0, errFlaky, 1, false},
+		{"succeeds after retries", 2, errFlaky, 3, false},
+		{"runs out of attempts", 10, errFlaky, 4, true},
+		{"stops on a permanent error", 10, Permanent(errFlaky), 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Reset()
+			p := Policy{Name: "test", MaxAttempts: 4, BaseDelay: time.Millisecond, Multiplier: 1}
+			calls := 0
+			err := p.Do(context.Background(), func(context.Context) error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, errFlaky)) {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+			stats := Stats()
+			if len(stats) != 1 || stats[0].Attempts != int64(calls) {
+				t.Errorf("stats = %+v, want %d attempts", stats, calls)
+			}
+		})
+	}
+}
+
+func TestDoStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := Policy{MaxAttempts: 10, BaseDelay: time.Hour}
+	errFlaky := errors.New("flaky")
+
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- p.Do(ctx, func(context.Context) error {
+			calls++
+			return errFlaky
+		})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errFlaky) || !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want the last error and context.Canceled", err)
+		}
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do kept waiting after the context was canceled")
+	}
+}
@@ -0,0 +1,214 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hydeh3r3/chirpy/internal/cache"
+	"github.com/hydeh3r3/chirpy/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Cached is a Store that serves chirps and rechirp counts from caches in
+// front of another Store. Chirps are written through on create, and counts
+// are dropped when a rechirp is added or removed. With in-process caches,
+// another instance's writes are only seen once the cached entry expires;
+// with Redis every instance sees them.
+type Cached struct {
+	Store
+	chirps   cache.Cache[uuid.UUID, database.Chirp]
+	rechirps cache.Cache[uuid.UUID, int64]
+}
+
+var _ Store = (*Cached)(nil)
+
+// NewCached serves chirps and rechirp counts from the given caches in front
+// of next
+func NewCached(next Store, chirps cache.Cache[uuid.UUID, database.Chirp], rechirps cache.Cache[uuid.UUID, int64]) *Cached {
+	return &Cached{
+		Store:    next,
+		chirps:   chirps,
+		rechirps: rechirps,
+	}
+}
+
+// Stats returns the hit and miss counts of each cache
+func (c *Cached) Stats() []cache.Stats {
+	return []cache.Stats{c.chirps.Stats(), c.rechirps.Stats()}
+}
+
+// ResetStats zeroes the hit and miss counts
+func (c *Cached) ResetStats() {
+	c.chirps.ResetStats()
+	c.rechirps.ResetStats()
+}
+
+// Unwrap returns the Store behind the cache
+func (c *Cached) Unwrap() Store {
+	return c.Store
+}
+
+// WithTx runs fn in a transaction on the Store behind the cache. Reads and
+// writes in the transaction bypass the cache. Once it commits, the chirps
+// it created are cached and the counts of chirps it rechirped or
+// un-rechirped are dropped; if it deleted every user, the caches are
+// emptied instead.
+func (c *Cached) WithTx(ctx context.Context, fn func(Store) error) error {
+	tx := &cachedTx{}
+	err := c.Store.WithTx(ctx, func(st Store) error {
+		tx.Store = st
+		return fn(tx)
+	})
+	if err != nil {
+		return err
+	}
+	if tx.purge {
+		c.chirps.Purge()
+		c.rechirps.Purge()
+		return nil
+	}
+	for _, chirp := range tx.created {
+		c.chirps.Set(chirp.ID, chirp)
+	}
+	for _, id := range tx.touched {
+		c.rechirps.Delete(id)
+	}
+	return nil
+}
+
+// cachedTx is a transaction on the Store behind a Cached, noting what it
+// changes that the cache holds
+type cachedTx struct {
+	Store
+	created []database.Chirp
+	touched []uuid.UUID // chirps whose rechirp counts changed
+	purge   bool        // every user was deleted
+}
+
+// Unwrap returns the transaction's Store
+func (t *cachedTx) Unwrap() Store {
+	return t.Store
+}
+
+// WithTx joins the transaction already running
+func (t *cachedTx) WithTx(ctx context.Context, fn func(Store) error) error {
+	return fn(t)
+}
+
+// DeleteAllUsers deletes every user in a tenant with their chirps and
+// rechirps and notes it
+func (t *cachedTx) DeleteAllUsers(ctx context.Context, tenantID uuid.UUID) error {
+	t.purge = true
+	return t.Store.DeleteAllUsers(ctx, tenantID)
+}
+
+// CreateChirp stores a new chirp and notes it
+func (t *cachedTx) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+	chirp, err := t.Store.CreateChirp(ctx, arg)
+	if err == nil {
+		t.created = append(t.created, chirp)
+	}
+	return chirp, err
+}
+
+// CreateRechirp stores a rechirp and notes the chirp
+func (t *cachedTx) CreateRechirp(ctx context.Context, arg database.CreateRechirpParams) (int64, error) {
+	t.touched = append(t.touched, arg.ChirpID)
+	return t.Store.CreateRechirp(ctx, arg)
+}
+
+// DeleteRechirp removes a rechirp and notes the chirp
+func (t *cachedTx) DeleteRechirp(ctx context.Context, arg database.DeleteRechirpParams) (int64, error) {
+	t.touched = append(t.touched, arg.ChirpID)
+	return t.Store.DeleteRechirp(ctx, arg)
+}
+
+// DeleteAllUsers deletes every user in a tenant with their chirps and
+// rechirps and empties the caches
+func (c *Cached) DeleteAllUsers(ctx context.Context, tenantID uuid.UUID) error {
+	err := c.Store.DeleteAllUsers(ctx, tenantID)
+	c.chirps.Purge()
+	c.rechirps.Purge()
+	return err
+}
+
+// CreateChirp stores a new chirp and caches it
+func (c *Cached) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+	chirp, err := c.Store.CreateChirp(ctx, arg)
+	if err == nil {
+		c.chirps.Set(chirp.ID, chirp)
+	}
+	return chirp, err
+}
+
+// GetChirp returns a chirp in a tenant by ID, from the cache if it is
+// there. A cached chirp from another tenant is not found.
+func (c *Cached) GetChirp(ctx context.Context, arg database.GetChirpParams) (database.Chirp, error) {
+	if chirp, ok := c.chirps.Get(arg.ID); ok {
+		if chirp.TenantID != arg.TenantID {
+			return database.Chirp{}, sql.ErrNoRows
+		}
+		return chirp, nil
+	}
+	chirp, err := c.Store.GetChirp(ctx, arg)
+	if err == nil {
+		c.chirps.Set(chirp.ID, chirp)
+	}
+	return chirp, err
+}
+
+// GetChirpsByIDs returns the chirps in a tenant that exist among arg.Ids,
+// fetching only those that aren't cached
+func (c *Cached) GetChirpsByIDs(ctx context.Context, arg database.GetChirpsByIDsParams) ([]database.Chirp, error) {
+	var chirps []database.Chirp
+	var missing []uuid.UUID
+	for _, id := range arg.Ids {
+		chirp, ok := c.chirps.Get(id)
+		switch {
+		case !ok:
+			missing = append(missing, id)
+		case chirp.TenantID == arg.TenantID:
+			chirps = append(chirps, chirp)
+		}
+	}
+	if len(missing) == 0 {
+		return chirps, nil
+	}
+
+	fetched, err := c.Store.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{TenantID: arg.TenantID, Ids: missing})
+	if err != nil {
+		return nil, err
+	}
+	for _, chirp := range fetched {
+		c.chirps.Set(chirp.ID, chirp)
+	}
+	return append(chirps, fetched...), nil
+}
+
+// CreateRechirp stores a rechirp and drops the chirp's cached count
+func (c *Cached) CreateRechirp(ctx context.Context, arg database.CreateRechirpParams) (int64, error) {
+	n, err := c.Store.CreateRechirp(ctx, arg)
+	c.rechirps.Delete(arg.ChirpID)
+	return n, err
+}
+
+// DeleteRechirp removes a rechirp and drops the chirp's cached count
+func (c *Cached) DeleteRechirp(ctx context.Context, arg database.DeleteRechirpParams) (int64, error) {
+	n, err := c.Store.DeleteRechirp(ctx, arg)
+	c.rechirps.Delete(arg.ChirpID)
+	return n, err
+}
+
+// CountRechirps returns how many times a chirp was rechirped, from the
+// cache if it is there
+func (c *Cached) CountRechirps(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	if count, ok := c.rechirps.Get(chirpID); ok {
+		return count, nil
+	}
+	count, err := c.Store.CountRechirps(ctx, chirpID)
+	if err == nil {
+		c.rechirps.Set(chirpID, count)
+	}
+	return count, err
+}
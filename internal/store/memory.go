@@ -0,0 +1,392 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Errors for constraints Postgres would enforce
+var (
+	ErrDuplicateEmail  = errors.New("store: email already in use")
+	ErrUnknownUser     = errors.New("store: user does not exist")
+	ErrDuplicateTenant = errors.New("store: tenant slug or host already in use")
+)
+
+// rechirpKey identifies a rechirp
+type rechirpKey struct {
+	userID  uuid.UUID
+	chirpID uuid.UUID
+}
+
+// Memory is a Store that keeps everything in memory. Data is lost when the
+// process exits.
+type Memory struct {
+	txMu     sync.Mutex // held for the whole of a transaction
+	mu       sync.RWMutex
+	tenants  map[uuid.UUID]database.Tenant
+	users    map[uuid.UUID]database.User
+	chirps   map[uuid.UUID]database.Chirp
+	rechirps map[rechirpKey]database.Rechirp
+}
+
+var _ Store = (*Memory)(nil)
+
+// NewMemory returns an in-memory Store with only the default tenant
+func NewMemory() *Memory {
+	return &Memory{
+		tenants: map[uuid.UUID]database.Tenant{
+			DefaultTenantID: {ID: DefaultTenantID, Slug: "default", Name: "Default"},
+		},
+		users:    make(map[uuid.UUID]database.User),
+		chirps:   make(map[uuid.UUID]database.Chirp),
+		rechirps: make(map[rechirpKey]database.Rechirp),
+	}
+}
+
+// WithTx runs fn in a transaction. Transactions run one at a time, and a
+// rollback restores everything as it was when the transaction began, so
+// writes made outside it in the meantime are undone too. That is good
+// enough for tests and demos.
+func (m *Memory) WithTx(ctx context.Context, fn func(Store) error) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+
+	m.mu.RLock()
+	tenants, users := maps.Clone(m.tenants), maps.Clone(m.users)
+	chirps, rechirps := maps.Clone(m.chirps), maps.Clone(m.rechirps)
+	m.mu.RUnlock()
+
+	committed := false
+	defer func() {
+		if !committed {
+			m.mu.Lock()
+			m.tenants, m.users, m.chirps, m.rechirps = tenants, users, chirps, rechirps
+			m.mu.Unlock()
+		}
+	}()
+	if err := fn(memoryTx{m}); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// memoryTx is a Memory inside a transaction
+type memoryTx struct {
+	*Memory
+}
+
+// WithTx joins the transaction already running
+func (t memoryTx) WithTx(ctx context.Context, fn func(Store) error) error {
+	return fn(t)
+}
+
+// CreateTenant stores a new tenant
+func (m *Memory) CreateTenant(ctx context.Context, arg database.CreateTenantParams) (database.Tenant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range m.tenants {
+		if t.Slug == arg.Slug || (arg.Host.Valid && t.Host == arg.Host) {
+			return database.Tenant{}, ErrDuplicateTenant
+		}
+	}
+	tenant := database.Tenant{
+		ID:        arg.ID,
+		Slug:      arg.Slug,
+		Name:      arg.Name,
+		Host:      arg.Host,
+		CreatedAt: arg.CreatedAt,
+	}
+	m.tenants[tenant.ID] = tenant
+	return tenant, nil
+}
+
+// GetTenantByHost finds the tenant serving a host name
+func (m *Memory) GetTenantByHost(ctx context.Context, host sql.NullString) (database.Tenant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, t := range m.tenants {
+		if host.Valid && t.Host == host {
+			return t, nil
+		}
+	}
+	return database.Tenant{}, sql.ErrNoRows
+}
+
+// GetTenantBySlug finds a tenant by its slug
+func (m *Memory) GetTenantBySlug(ctx context.Context, slug string) (database.Tenant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, t := range m.tenants {
+		if t.Slug == slug {
+			return t, nil
+		}
+	}
+	return database.Tenant{}, sql.ErrNoRows
+}
+
+// ListTenants returns every tenant ordered by slug
+func (m *Memory) ListTenants(ctx context.Context) ([]database.Tenant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tenants := slices.Collect(maps.Values(m.tenants))
+	slices.SortFunc(tenants, func(a, b database.Tenant) int { return strings.Compare(a.Slug, b.Slug) })
+	return tenants, nil
+}
+
+// CreateUser stores a new user. Emails are unique within a tenant.
+func (m *Memory) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.TenantID == arg.TenantID && u.Email == arg.Email {
+			return database.User{}, ErrDuplicateEmail
+		}
+	}
+	user := database.User{
+		ID:            arg.ID,
+		CreatedAt:     arg.CreatedAt,
+		UpdatedAt:     arg.UpdatedAt,
+		Email:         arg.Email,
+		PostingSecret: arg.PostingSecret,
+		TenantID:      arg.TenantID,
+	}
+	m.users[user.ID] = user
+	return user, nil
+}
+
+// GetUser returns a user in a tenant by ID
+func (m *Memory) GetUser(ctx context.Context, arg database.GetUserParams) (database.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[arg.ID]
+	if !ok || user.TenantID != arg.TenantID {
+		return database.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+// GetUserByPostingSecret finds a user by their posting secret
+func (m *Memory) GetUserByPostingSecret(ctx context.Context, postingSecret string) (database.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, u := range m.users {
+		if u.PostingSecret == postingSecret {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+// UpdatePostingSecret replaces a user's posting secret
+func (m *Memory) UpdatePostingSecret(ctx context.Context, arg database.UpdatePostingSecretParams) (database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[arg.ID]
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+	user.PostingSecret = arg.PostingSecret
+	user.UpdatedAt = arg.UpdatedAt
+	m.users[user.ID] = user
+	return user, nil
+}
+
+// CountUsers returns the number of users in a tenant
+func (m *Memory) CountUsers(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var n int64
+	for _, u := range m.users {
+		if u.TenantID == tenantID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteAllUsers deletes every user in a tenant with their chirps and
+// rechirps
+func (m *Memory) DeleteAllUsers(ctx context.Context, tenantID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	maps.DeleteFunc(m.users, func(_ uuid.UUID, u database.User) bool { return u.TenantID == tenantID })
+	maps.DeleteFunc(m.chirps, func(_ uuid.UUID, c database.Chirp) bool {
+		_, ok := m.users[c.UserID]
+		return !ok
+	})
+	maps.DeleteFunc(m.rechirps, func(key rechirpKey, _ database.Rechirp) bool {
+		_, userOK := m.users[key.userID]
+		_, chirpOK := m.chirps[key.chirpID]
+		return !userOK || !chirpOK
+	})
+	return nil
+}
+
+// CreateChirp stores a new chirp
+func (m *Memory) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return database.Chirp{}, ErrUnknownUser
+	}
+	chirp := database.Chirp{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Body:      arg.Body,
+		UserID:    arg.UserID,
+		TenantID:  arg.TenantID,
+	}
+	m.chirps[chirp.ID] = chirp
+	return chirp, nil
+}
+
+// GetChirp returns a chirp in a tenant by ID
+func (m *Memory) GetChirp(ctx context.Context, arg database.GetChirpParams) (database.Chirp, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chirp, ok := m.chirps[arg.ID]
+	if !ok || chirp.TenantID != arg.TenantID {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return chirp, nil
+}
+
+// GetChirpsByIDs returns the chirps in a tenant that exist among arg.Ids
+func (m *Memory) GetChirpsByIDs(ctx context.Context, arg database.GetChirpsByIDsParams) ([]database.Chirp, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var chirps []database.Chirp
+	seen := make(map[uuid.UUID]bool, len(arg.Ids))
+	for _, id := range arg.Ids {
+		if chirp, ok := m.chirps[id]; ok && chirp.TenantID == arg.TenantID && !seen[id] {
+			seen[id] = true
+			chirps = append(chirps, chirp)
+		}
+	}
+	return chirps, nil
+}
+
+// GetRecentDuplicateChirp returns the newest chirp by the user with the same
+// body created at or after arg.CreatedAt
+func (m *Memory) GetRecentDuplicateChirp(ctx context.Context, arg database.GetRecentDuplicateChirpParams) (database.Chirp, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found *database.Chirp
+	for _, c := range m.chirps {
+		if c.UserID != arg.UserID || c.Body != arg.Body || c.CreatedAt.Before(arg.CreatedAt) {
+			continue
+		}
+		if found == nil || c.CreatedAt.After(found.CreatedAt) {
+			found = &c
+		}
+	}
+	if found == nil {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return *found, nil
+}
+
+// CountChirps returns the number of chirps in a tenant
+func (m *Memory) CountChirps(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var n int64
+	for _, c := range m.chirps {
+		if c.TenantID == tenantID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// CreateRechirp records a rechirp and returns how many were added, doing
+// nothing if it already exists
+func (m *Memory) CreateRechirp(ctx context.Context, arg database.CreateRechirpParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return 0, ErrUnknownUser
+	}
+	key := rechirpKey{userID: arg.UserID, chirpID: arg.ChirpID}
+	if _, ok := m.rechirps[key]; ok {
+		return 0, nil
+	}
+	m.rechirps[key] = database.Rechirp{UserID: arg.UserID, ChirpID: arg.ChirpID, CreatedAt: arg.CreatedAt}
+	return 1, nil
+}
+
+// DeleteRechirp removes a rechirp and returns how many were removed
+func (m *Memory) DeleteRechirp(ctx context.Context, arg database.DeleteRechirpParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := rechirpKey{userID: arg.UserID, chirpID: arg.ChirpID}
+	if _, ok := m.rechirps[key]; !ok {
+		return 0, nil
+	}
+	delete(m.rechirps, key)
+	return 1, nil
+}
+
+// CountRechirps returns how many times a chirp was rechirped
+func (m *Memory) CountRechirps(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var n int64
+	for key := range m.rechirps {
+		if key.chirpID == chirpID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// CountRechirpsByChirpIDs returns rechirp counts for the chirps in chirpIDs
+// that have any
+func (m *Memory) CountRechirpsByChirpIDs(ctx context.Context, chirpIDs []uuid.UUID) ([]database.CountRechirpsByChirpIDsRow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := make(map[uuid.UUID]bool, len(chirpIDs))
+	for _, id := range chirpIDs {
+		wanted[id] = true
+	}
+	counts := map[uuid.UUID]int64{}
+	for key := range m.rechirps {
+		if wanted[key.chirpID] {
+			counts[key.chirpID]++
+		}
+	}
+	var rows []database.CountRechirpsByChirpIDsRow
+	for id, n := range counts {
+		rows = append(rows, database.CountRechirpsByChirpIDsRow{ChirpID: id, Count: n})
+	}
+	return rows, nil
+}
@@ -0,0 +1,28 @@
+-- SQLite schema for the tables behind the store. Postgres migrations live
+-- in sql/schema and are run with goose.
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    posting_secret TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE chirps (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    body TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX chirps_user_id_created_at_idx ON chirps (user_id, created_at);
+
+CREATE TABLE rechirps (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id TEXT NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX rechirps_chirp_id_idx ON rechirps (chirp_id);
@@ -0,0 +1,21 @@
+-- Tenants, as in the Postgres migration 20250315000000_create_tenants.
+-- users.email stays unique across tenants here: SQLite can't drop the
+-- constraint without rebuilding the table.
+CREATE TABLE tenants (
+    id TEXT PRIMARY KEY,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    host TEXT UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO tenants (id, slug, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default', 'Default', CURRENT_TIMESTAMP);
+
+ALTER TABLE users
+    ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+
+ALTER TABLE chirps
+    ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+
+CREATE INDEX chirps_tenant_id_created_at_idx ON chirps (tenant_id, created_at);
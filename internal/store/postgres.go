@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/tracing"
+)
+
+// Postgres is a Store backed by the generated Postgres queries
+type Postgres struct {
+	*database.Queries
+	db *sql.DB // nil inside a transaction
+}
+
+var _ Store = (*Postgres)(nil)
+
+// NewPostgres returns a Store backed by Postgres, tracing its queries
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{Queries: database.New(tracing.DB(db, "postgresql")), db: db}
+}
+
+// WithTx runs fn in a transaction. It shadows Queries.WithTx, which only
+// rebinds the queries to a transaction the caller manages (and would drop
+// their tracing).
+func (p *Postgres) WithTx(ctx context.Context, fn func(Store) error) error {
+	if p.db == nil {
+		return fn(p)
+	}
+	return runTx(ctx, p.db, func(tx *sql.Tx) error {
+		return fn(&Postgres{Queries: database.New(tracing.DB(tx, "postgresql"))})
+	})
+}
@@ -0,0 +1,353 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/tracing"
+
+	"github.com/google/uuid"
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// OpenSQLite opens the SQLite database file at path with foreign keys on and
+// a busy timeout, so concurrent writers wait instead of failing. The caller
+// must import a "sqlite3" driver such as github.com/mattn/go-sqlite3.
+func OpenSQLite(path string) (*sql.DB, error) {
+	return sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL")
+}
+
+// MigrateSQLite applies any embedded SQLite migrations that haven't run yet.
+// Each migration runs in its own transaction and is recorded in
+// schema_migrations.
+func MigrateSQLite(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	names, err := fs.Glob(sqliteMigrations, "migrations/sqlite/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		version := strings.TrimSuffix(name[strings.LastIndex(name, "/")+1:], ".sql")
+		if err := applySQLiteMigration(ctx, db, name, version); err != nil {
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// applySQLiteMigration runs one migration file unless it is already recorded
+func applySQLiteMigration(ctx context.Context, db *sql.DB, name, version string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`, version).Scan(&applied)
+	if err != nil || applied {
+		return err
+	}
+
+	stmts, err := sqliteMigrations.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, string(stmts)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SQLite is a Store backed by a SQLite database, for small deployments
+// without Postgres
+type SQLite struct {
+	db   database.DBTX
+	conn *sql.DB // nil inside a transaction
+}
+
+var _ Store = (*SQLite)(nil)
+
+// NewSQLite returns a Store backed by db, which must be migrated with
+// MigrateSQLite
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{db: tracing.DB(db, "sqlite"), conn: db}
+}
+
+// WithTx runs fn in a transaction
+func (s *SQLite) WithTx(ctx context.Context, fn func(Store) error) error {
+	if s.conn == nil {
+		return fn(s)
+	}
+	return runTx(ctx, s.conn, func(tx *sql.Tx) error {
+		return fn(&SQLite{db: tracing.DB(tx, "sqlite")})
+	})
+}
+
+const sqliteTenantColumns = `id, slug, name, host, created_at`
+
+const sqliteUserColumns = `id, created_at, updated_at, email, posting_secret, tenant_id`
+
+const sqliteChirpColumns = `id, created_at, updated_at, body, user_id, tenant_id`
+
+// sqliteInList returns "?, ?, ..." and the arguments for an IN list of ids.
+// SQLite has no array parameters.
+func sqliteInList(ids []uuid.UUID) (string, []any) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
+
+// scanTenant scans a row of sqliteTenantColumns
+func scanTenant(row interface{ Scan(...any) error }) (database.Tenant, error) {
+	var t database.Tenant
+	err := row.Scan(&t.ID, &t.Slug, &t.Name, &t.Host, &t.CreatedAt)
+	return t, err
+}
+
+// scanUser scans a row of sqliteUserColumns
+func scanUser(row *sql.Row) (database.User, error) {
+	var u database.User
+	err := row.Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt, &u.Email, &u.PostingSecret, &u.TenantID)
+	return u, err
+}
+
+// scanChirp scans a row of sqliteChirpColumns
+func scanChirp(row *sql.Row) (database.Chirp, error) {
+	var c database.Chirp
+	err := row.Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt, &c.Body, &c.UserID, &c.TenantID)
+	return c, err
+}
+
+// CreateTenant stores a new tenant
+func (s *SQLite) CreateTenant(ctx context.Context, arg database.CreateTenantParams) (database.Tenant, error) {
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO tenants (`+sqliteTenantColumns+`) VALUES (?, ?, ?, ?, ?) RETURNING `+sqliteTenantColumns,
+		arg.ID, arg.Slug, arg.Name, arg.Host, arg.CreatedAt,
+	)
+	return scanTenant(row)
+}
+
+// GetTenantByHost finds the tenant serving a host name
+func (s *SQLite) GetTenantByHost(ctx context.Context, host sql.NullString) (database.Tenant, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+sqliteTenantColumns+` FROM tenants WHERE host = ?`, host)
+	return scanTenant(row)
+}
+
+// GetTenantBySlug finds a tenant by its slug
+func (s *SQLite) GetTenantBySlug(ctx context.Context, slug string) (database.Tenant, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+sqliteTenantColumns+` FROM tenants WHERE slug = ?`, slug)
+	return scanTenant(row)
+}
+
+// ListTenants returns every tenant ordered by slug
+func (s *SQLite) ListTenants(ctx context.Context) ([]database.Tenant, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+sqliteTenantColumns+` FROM tenants ORDER BY slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []database.Tenant
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, rows.Err()
+}
+
+// CreateUser stores a new user. Unlike Postgres, emails are unique across
+// tenants.
+func (s *SQLite) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO users (`+sqliteUserColumns+`) VALUES (?, ?, ?, ?, ?, ?) RETURNING `+sqliteUserColumns,
+		arg.ID, arg.CreatedAt, arg.UpdatedAt, arg.Email, arg.PostingSecret, arg.TenantID,
+	)
+	return scanUser(row)
+}
+
+// GetUser returns a user in a tenant by ID
+func (s *SQLite) GetUser(ctx context.Context, arg database.GetUserParams) (database.User, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+sqliteUserColumns+` FROM users WHERE id = ? AND tenant_id = ?`,
+		arg.ID, arg.TenantID,
+	)
+	return scanUser(row)
+}
+
+// GetUserByPostingSecret finds a user by their posting secret
+func (s *SQLite) GetUserByPostingSecret(ctx context.Context, postingSecret string) (database.User, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+sqliteUserColumns+` FROM users WHERE posting_secret = ?`,
+		postingSecret,
+	)
+	return scanUser(row)
+}
+
+// UpdatePostingSecret replaces a user's posting secret
+func (s *SQLite) UpdatePostingSecret(ctx context.Context, arg database.UpdatePostingSecretParams) (database.User, error) {
+	row := s.db.QueryRowContext(ctx,
+		`UPDATE users SET posting_secret = ?, updated_at = ? WHERE id = ? RETURNING `+sqliteUserColumns,
+		arg.PostingSecret, arg.UpdatedAt, arg.ID,
+	)
+	return scanUser(row)
+}
+
+// CountUsers returns the number of users in a tenant
+func (s *SQLite) CountUsers(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var n int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE tenant_id = ?`, tenantID).Scan(&n)
+	return n, err
+}
+
+// DeleteAllUsers deletes every user in a tenant, and through foreign keys
+// their chirps and rechirps
+func (s *SQLite) DeleteAllUsers(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM users WHERE tenant_id = ?`, tenantID)
+	return err
+}
+
+// CreateChirp stores a new chirp
+func (s *SQLite) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO chirps (`+sqliteChirpColumns+`) VALUES (?, ?, ?, ?, ?, ?) RETURNING `+sqliteChirpColumns,
+		arg.ID, arg.CreatedAt, arg.UpdatedAt, arg.Body, arg.UserID, arg.TenantID,
+	)
+	return scanChirp(row)
+}
+
+// GetChirp returns a chirp in a tenant by ID
+func (s *SQLite) GetChirp(ctx context.Context, arg database.GetChirpParams) (database.Chirp, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+sqliteChirpColumns+` FROM chirps WHERE id = ? AND tenant_id = ?`,
+		arg.ID, arg.TenantID,
+	)
+	return scanChirp(row)
+}
+
+// GetChirpsByIDs returns the chirps in a tenant that exist among arg.Ids
+func (s *SQLite) GetChirpsByIDs(ctx context.Context, arg database.GetChirpsByIDsParams) ([]database.Chirp, error) {
+	if len(arg.Ids) == 0 {
+		return nil, nil
+	}
+	placeholders, args := sqliteInList(arg.Ids)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+sqliteChirpColumns+` FROM chirps WHERE tenant_id = ? AND id IN (`+placeholders+`)`,
+		append([]any{arg.TenantID}, args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chirps []database.Chirp
+	for rows.Next() {
+		var c database.Chirp
+		if err := rows.Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt, &c.Body, &c.UserID, &c.TenantID); err != nil {
+			return nil, err
+		}
+		chirps = append(chirps, c)
+	}
+	return chirps, rows.Err()
+}
+
+// GetRecentDuplicateChirp returns the newest chirp by the user with the same
+// body created at or after arg.CreatedAt
+func (s *SQLite) GetRecentDuplicateChirp(ctx context.Context, arg database.GetRecentDuplicateChirpParams) (database.Chirp, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+sqliteChirpColumns+` FROM chirps
+WHERE user_id = ? AND body = ? AND created_at >= ?
+ORDER BY created_at DESC
+LIMIT 1`,
+		arg.UserID, arg.Body, arg.CreatedAt,
+	)
+	return scanChirp(row)
+}
+
+// CountChirps returns the number of chirps in a tenant
+func (s *SQLite) CountChirps(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var n int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM chirps WHERE tenant_id = ?`, tenantID).Scan(&n)
+	return n, err
+}
+
+// CreateRechirp records a rechirp and returns how many were added, doing
+// nothing if it already exists
+func (s *SQLite) CreateRechirp(ctx context.Context, arg database.CreateRechirpParams) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO rechirps (user_id, chirp_id, created_at) VALUES (?, ?, ?)
+ON CONFLICT (user_id, chirp_id) DO NOTHING`,
+		arg.UserID, arg.ChirpID, arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteRechirp removes a rechirp and returns how many were removed
+func (s *SQLite) DeleteRechirp(ctx context.Context, arg database.DeleteRechirpParams) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM rechirps WHERE user_id = ? AND chirp_id = ?`,
+		arg.UserID, arg.ChirpID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CountRechirps returns how many times a chirp was rechirped
+func (s *SQLite) CountRechirps(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	var n int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM rechirps WHERE chirp_id = ?`, chirpID).Scan(&n)
+	return n, err
+}
+
+// CountRechirpsByChirpIDs returns rechirp counts for the chirps in chirpIDs
+// that have any
+func (s *SQLite) CountRechirpsByChirpIDs(ctx context.Context, chirpIDs []uuid.UUID) ([]database.CountRechirpsByChirpIDsRow, error) {
+	if len(chirpIDs) == 0 {
+		return nil, nil
+	}
+	placeholders, args := sqliteInList(chirpIDs)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT chirp_id, COUNT(*) FROM rechirps WHERE chirp_id IN (`+placeholders+`) GROUP BY chirp_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []database.CountRechirpsByChirpIDsRow
+	for rows.Next() {
+		var row database.CountRechirpsByChirpIDsRow
+		if err := rows.Scan(&row.ChirpID, &row.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, row)
+	}
+	return counts, rows.Err()
+}
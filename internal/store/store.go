@@ -0,0 +1,111 @@
+// Package store defines the storage the core chirp and user endpoints need,
+// so the server can run on Postgres or, for demos, entirely in memory.
+//
+// Stores use the sqlc models from internal/database and, like sqlc, return
+// sql.ErrNoRows when a single row is not found.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/tracing"
+
+	"github.com/google/uuid"
+)
+
+// UserStore stores users
+type UserStore interface {
+	CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error)
+	// GetUser only finds users in arg.TenantID
+	GetUser(ctx context.Context, arg database.GetUserParams) (database.User, error)
+	GetUserByPostingSecret(ctx context.Context, postingSecret string) (database.User, error)
+	UpdatePostingSecret(ctx context.Context, arg database.UpdatePostingSecretParams) (database.User, error)
+	CountUsers(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	// DeleteAllUsers deletes every user in tenantID, and their chirps and
+	// rechirps
+	DeleteAllUsers(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// ChirpStore stores chirps and rechirps
+type ChirpStore interface {
+	CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error)
+	// GetChirp only finds chirps in arg.TenantID
+	GetChirp(ctx context.Context, arg database.GetChirpParams) (database.Chirp, error)
+	// GetChirpsByIDs returns the chirps in arg.TenantID that exist among
+	// arg.Ids, in any order
+	GetChirpsByIDs(ctx context.Context, arg database.GetChirpsByIDsParams) ([]database.Chirp, error)
+	GetRecentDuplicateChirp(ctx context.Context, arg database.GetRecentDuplicateChirpParams) (database.Chirp, error)
+	CountChirps(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	CreateRechirp(ctx context.Context, arg database.CreateRechirpParams) (int64, error)
+	DeleteRechirp(ctx context.Context, arg database.DeleteRechirpParams) (int64, error)
+	CountRechirps(ctx context.Context, chirpID uuid.UUID) (int64, error)
+	// CountRechirpsByChirpIDs returns counts only for chirps with rechirps
+	CountRechirpsByChirpIDs(ctx context.Context, chirpIDs []uuid.UUID) ([]database.CountRechirpsByChirpIDsRow, error)
+}
+
+// TenantStore stores the communities users and chirps belong to
+type TenantStore interface {
+	CreateTenant(ctx context.Context, arg database.CreateTenantParams) (database.Tenant, error)
+	GetTenantByHost(ctx context.Context, host sql.NullString) (database.Tenant, error)
+	GetTenantBySlug(ctx context.Context, slug string) (database.Tenant, error)
+	ListTenants(ctx context.Context) ([]database.Tenant, error)
+}
+
+// DefaultTenantID is the tenant that always exists. It owns everything
+// created before tenants were added and serves hosts no other tenant
+// claims.
+var DefaultTenantID = uuid.Nil
+
+// Store is everything the core endpoints need
+type Store interface {
+	UserStore
+	ChirpStore
+	TenantStore
+	// WithTx runs fn in a transaction, passing a Store whose calls are part
+	// of it. The transaction commits if fn returns nil and rolls back if it
+	// returns an error or panics. Calling WithTx on the Store passed to fn
+	// joins the same transaction.
+	WithTx(ctx context.Context, fn func(Store) error) error
+}
+
+// NewPostingSecret returns a random secret for a user's posting address
+func NewPostingSecret() string {
+	return strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// PostgresQueries returns the generated queries behind st, or nil if st
+// isn't backed by Postgres. For the Store passed to a WithTx callback they
+// run on that transaction, so Postgres-only writes can join it.
+func PostgresQueries(st Store) *database.Queries {
+	for st != nil {
+		switch s := st.(type) {
+		case *Postgres:
+			return s.Queries
+		case interface{ Unwrap() Store }:
+			st = s.Unwrap()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// runTx runs fn in a transaction on db, committing if it returns nil
+func runTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) (err error) {
+	ctx, span := tracing.Start(ctx, "transaction")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
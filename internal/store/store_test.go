@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/cache"
+	"github.com/hydeh3r3/chirpy/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryWithTx(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createUser := func(st Store, email string) {
+		t.Helper()
+		_, err := st.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), Email: email})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A failed transaction leaves nothing behind
+	errFail := errors.New("fail")
+	err := m.WithTx(ctx, func(tx Store) error {
+		createUser(tx, "a@example.com")
+		return errFail
+	})
+	if !errors.Is(err, errFail) {
+		t.Fatalf("err = %v, want %v", err, errFail)
+	}
+	if n, _ := m.CountUsers(ctx, DefaultTenantID); n != 0 {
+		t.Errorf("users after rollback = %d, want 0", n)
+	}
+
+	// So does a panic
+	func() {
+		defer func() { recover() }()
+		m.WithTx(ctx, func(tx Store) error {
+			createUser(tx, "b@example.com")
+			panic("boom")
+		})
+	}()
+	if n, _ := m.CountUsers(ctx, DefaultTenantID); n != 0 {
+		t.Errorf("users after panic = %d, want 0", n)
+	}
+
+	// A nested WithTx joins the outer transaction
+	err = m.WithTx(ctx, func(tx Store) error {
+		createUser(tx, "c@example.com")
+		return tx.WithTx(ctx, func(tx Store) error {
+			createUser(tx, "d@example.com")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := m.CountUsers(ctx, DefaultTenantID); n != 2 {
+		t.Errorf("users after commit = %d, want 2", n)
+	}
+}
+
+func TestCachedWithTx(t *testing.T) {
+	ctx := context.Background()
+	c := NewCached(NewMemory(),
+		cache.New[uuid.UUID, database.Chirp]("chirps", 10, time.Minute),
+		cache.New[uuid.UUID, int64]("rechirp_counts", 10, time.Minute))
+	user, err := c.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), Email: "a@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chirp, err := c.CreateChirp(ctx, database.CreateChirpParams{ID: uuid.New(), Body: "hi", UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.CountRechirps(ctx, chirp.ID)
+
+	// Nothing from a rolled back transaction is cached
+	var rolledBack database.Chirp
+	c.WithTx(ctx, func(tx Store) error {
+		rolledBack, _ = tx.CreateChirp(ctx, database.CreateChirpParams{ID: uuid.New(), Body: "gone", UserID: user.ID})
+		return errors.New("fail")
+	})
+	if _, err := c.GetChirp(ctx, database.GetChirpParams{ID: rolledBack.ID}); err == nil {
+		t.Error("rolled back chirp is still readable")
+	}
+
+	err = c.WithTx(ctx, func(tx Store) error {
+		_, err := tx.CreateRechirp(ctx, database.CreateRechirpParams{UserID: user.ID, ChirpID: chirp.ID})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := c.CountRechirps(ctx, chirp.ID); n != 1 {
+		t.Errorf("rechirps after commit = %d, want 1", n)
+	}
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DBTX is the database handle the generated queries run on, satisfied by
+// *sql.DB and *sql.Tx
+type DBTX interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+// DB returns db with a span around each call made within a traced request
+// or job; calls without a span in their context, such as the job queue
+// polling for work, aren't traced. system is the database, e.g.
+// "postgresql". Spans cover the call, not reading its rows.
+func DB(db DBTX, system string) DBTX {
+	return &tracedDB{db: db, system: system}
+}
+
+// tracedDB wraps a DBTX with spans
+type tracedDB struct {
+	db     DBTX
+	system string
+}
+
+func (d *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := d.start(ctx, query)
+	res, err := d.db.ExecContext(ctx, query, args...)
+	End(span, err)
+	return res, err
+}
+
+func (d *tracedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := d.start(ctx, query)
+	stmt, err := d.db.PrepareContext(ctx, query)
+	End(span, err)
+	return stmt, err
+}
+
+func (d *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := d.start(ctx, query)
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	End(span, err)
+	return rows, err
+}
+
+func (d *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := d.start(ctx, query)
+	row := d.db.QueryRowContext(ctx, query, args...)
+	End(span, row.Err())
+	return row
+}
+
+// start starts a span for query, or returns the span already in ctx if it
+// isn't part of a trace
+func (d *tracedDB) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return Start(ctx, queryName(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemKey.String(d.system),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// queryName names a query's span: the name sqlc gives it in its leading
+// "-- name: GetChirp :one" comment, or else its first keyword, e.g. SELECT
+func queryName(query string) string {
+	query = strings.TrimSpace(query)
+	if rest, ok := strings.CutPrefix(query, "-- name: "); ok {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	if fields := strings.Fields(query); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return "query"
+}
@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// record installs a tracer provider that keeps finished spans in memory
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"-- name: GetChirp :one\nSELECT id FROM chirps WHERE id = $1", "GetChirp"},
+		{"\n  select id from chirps", "SELECT"},
+		{"", "query"},
+	}
+	for _, tt := range tests {
+		if got := queryName(tt.query); got != tt.want {
+			t.Errorf("queryName(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+// fakeDB answers every Exec
+type fakeDB struct {
+	DBTX
+}
+
+func (fakeDB) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func TestDBOnlyTracesWithinATrace(t *testing.T) {
+	recorder := record(t)
+	db := DB(fakeDB{}, "postgresql")
+	query := "-- name: DeleteAllUsers :exec\nDELETE FROM users"
+
+	// Without a span in the context, e.g. job polling
+	if _, err := db.ExecContext(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Fatalf("traced %d spans without a parent", len(spans))
+	}
+
+	ctx, parent := Start(context.Background(), "request")
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "DeleteAllUsers" {
+		t.Fatalf("spans = %v, want DeleteAllUsers then request", spans)
+	}
+	if spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("query span isn't a child of the request")
+	}
+}
+
+func TestTransportSendsTraceparent(t *testing.T) {
+	recorder := record(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Traceparent")))
+	}))
+	defer srv.Close()
+
+	ctx, parent := Start(context.Background(), "request")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	parent.End()
+
+	if traceID := parent.SpanContext().TraceID().String(); !strings.Contains(string(sent), traceID) {
+		t.Errorf("traceparent = %q, want trace %s", sent, traceID)
+	}
+
+	if req.Header.Get("Traceparent") != "" {
+		t.Errorf("the caller's request was modified")
+	}
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "HTTP GET" {
+		t.Fatalf("spans = %v, want HTTP GET then request", spans)
+	}
+	if spans[0].SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("client span is in another trace")
+	}
+}
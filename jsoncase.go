@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+)
+
+// negotiateJSONCase picks the field naming for a response from the case
+// parameter of an application/json Accept entry, such as
+// "application/json; case=camel", falling back to def
+func negotiateJSONCase(header, def string) string {
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || (mediaType != "application/json" && mediaType != "*/*") {
+			continue
+		}
+		switch c := params["case"]; c {
+		case config.JSONCaseSnake, config.JSONCaseCamel:
+			return c
+		}
+	}
+	return def
+}
+
+// camelCase converts a snake_case name such as next_cursor to nextCursor
+func camelCase(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_' && b.Len() > 0:
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// renameJSONKeys rewrites every object key in a JSON document with rename,
+// keeping the order of fields and leaving values untouched
+func renameJSONKeys(src []byte, rename func(string) string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+
+	// Each open object or array on the stack, and whether the next token
+	// in an object is a key
+	type frame struct {
+		object bool
+		key    bool
+		first  bool
+	}
+	var stack []frame
+	var out bytes.Buffer
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) && len(stack) == 0 {
+			break
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Separators before this token
+		isKey := false
+		if delim, ok := tok.(json.Delim); !ok || (delim != '}' && delim != ']') {
+			if n := len(stack); n > 0 {
+				top := &stack[n-1]
+				if top.object {
+					if top.key {
+						isKey = true
+						if !top.first {
+							out.WriteByte(',')
+						}
+					} else {
+						out.WriteByte(':')
+					}
+					top.key = !top.key
+				} else if !top.first {
+					out.WriteByte(',')
+				}
+				top.first = false
+			}
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			out.WriteRune(rune(v))
+			switch v {
+			case '{':
+				stack = append(stack, frame{object: true, key: true, first: true})
+			case '[':
+				stack = append(stack, frame{first: true})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if isKey {
+				v = rename(v)
+			}
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(encoded)
+		case json.Number:
+			out.WriteString(v.String())
+		case bool:
+			if v {
+				out.WriteString("true")
+			} else {
+				out.WriteString("false")
+			}
+		case nil:
+			out.WriteString("null")
+		}
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// jsonCaseWriter buffers a JSON response so its keys can be renamed once
+// the handler is done. Other content types are passed straight through.
+type jsonCaseWriter struct {
+	http.ResponseWriter
+	status    int
+	decided   bool
+	buffering bool
+	buf       bytes.Buffer
+}
+
+func (cw *jsonCaseWriter) WriteHeader(status int) {
+	if cw.decided {
+		return
+	}
+	cw.decided = true
+	cw.status = status
+
+	// Error responses are JSON without a Content-Type
+	contentType := cw.Header().Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	cw.buffering = contentType == "" || mediaType == "application/json"
+	if !cw.buffering {
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (cw *jsonCaseWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.buffering {
+		return cw.buf.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush sends what has been written so far. Buffered JSON can only be
+// renamed once it is complete, so it stays buffered until finish.
+func (cw *jsonCaseWriter) Flush() {
+	if cw.decided && !cw.buffering {
+		http.NewResponseController(cw.ResponseWriter).Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *jsonCaseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// finish renames the keys of a buffered response and writes it. A body
+// that isn't valid JSON is written as it was.
+func (cw *jsonCaseWriter) finish() {
+	if !cw.decided || !cw.buffering {
+		return
+	}
+	body := cw.buf.Bytes()
+	if renamed, err := renameJSONKeys(body, camelCase); err == nil && len(body) > 0 {
+		body = renamed
+	}
+	cw.Header().Del("Content-Length")
+	cw.ResponseWriter.WriteHeader(cw.status)
+	cw.ResponseWriter.Write(body)
+}
+
+// middlewareJSONCase writes JSON responses with camelCase field names when
+// the client asks for them in Accept, or when that is the configured
+// default. snake_case responses are passed through untouched. Request
+// bodies are always snake_case.
+func middlewareJSONCase(def string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if negotiateJSONCase(r.Header.Get("Accept"), def) != config.JSONCaseCamel {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &jsonCaseWriter{ResponseWriter: w}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+)
+
+func TestRenameJSONKeys(t *testing.T) {
+	src := `{"next_cursor":"a_b","chirps":[{"user_id":1,"link_previews":[],"poll":null,"ok":true}],"x":{"created_at":"<t>"}}`
+	got, err := renameJSONKeys([]byte(src), camelCase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keys are renamed in place; values, including strings with
+	// underscores, are not. HTML is escaped like json.Encoder does.
+	want := `{"nextCursor":"a_b","chirps":[{"userId":1,"linkPreviews":[],"poll":null,"ok":true}],"x":{"createdAt":"\u003ct\u003e"}}` + "\n"
+	if string(got) != want {
+		t.Errorf("renameJSONKeys =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := renameJSONKeys([]byte(`{"a":`), camelCase); err == nil {
+		t.Error("expected an error for truncated JSON")
+	}
+}
+
+func TestNegotiateJSONCase(t *testing.T) {
+	tests := []struct {
+		header string
+		def    string
+		want   string
+	}{
+		{"", config.JSONCaseSnake, config.JSONCaseSnake},
+		{"", config.JSONCaseCamel, config.JSONCaseCamel},
+		{"application/json; case=camel", config.JSONCaseSnake, config.JSONCaseCamel},
+		{"text/html, application/json;case=snake", config.JSONCaseCamel, config.JSONCaseSnake},
+		{"application/json; case=kebab", config.JSONCaseSnake, config.JSONCaseSnake},
+	}
+	for _, tt := range tests {
+		if got := negotiateJSONCase(tt.header, tt.def); got != tt.want {
+			t.Errorf("negotiateJSONCase(%q, %q) = %q, want %q", tt.header, tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestCamelCaseResponses(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	user := srv.createUser("a@example.com")
+
+	rec := srv.do(http.MethodGet, "/admin/users/"+user.ID, "", "Accept", "application/json; case=camel")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"createdAt"`) || strings.Contains(body, `"created_at"`) {
+		t.Errorf("body = %s, want camelCase keys", body)
+	}
+
+	// Errors follow the same convention
+	rec = srv.do(http.MethodPost, "/api/chirps", `{"body":"hi"}`, "Accept", "application/json; case=camel")
+	if body := rec.Body.String(); rec.Code != http.StatusBadRequest || !strings.Contains(body, `"field":"user_id"`) {
+		t.Errorf("error = %d %s", rec.Code, body)
+	}
+
+	cfg := testConfig()
+	cfg.JSONCase = config.JSONCaseCamel
+	srv = newTestServer(t, cfg)
+	user = srv.createUser("a@example.com")
+	rec = srv.do(http.MethodGet, "/admin/users/"+user.ID, "")
+	if !strings.Contains(rec.Body.String(), `"createdAt"`) {
+		t.Errorf("body = %s, want camelCase keys by default", rec.Body)
+	}
+	rec = srv.do(http.MethodGet, "/admin/users/"+user.ID, "", "Accept", "application/json; case=snake")
+	if !strings.Contains(rec.Body.String(), `"created_at"`) {
+		t.Errorf("body = %s, want snake_case keys when asked", rec.Body)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/retry"
+)
+
+// linkPreviewTTL is how long a fetched preview is reused before fetching it again
+const linkPreviewTTL = 24 * time.Hour
+
+// linkPreviewResponse represents a link preview attached to a chirp
+type linkPreviewResponse struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+}
+
+// chirpURLs returns the distinct links in a chirp body, in order of appearance
+func chirpURLs(body string) []string {
+	seen := map[string]bool{}
+	var urls []string
+	for _, u := range urlPattern.FindAllString(body, -1) {
+		u = trimURL(u)
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// trimURL drops punctuation that ends the sentence around a link rather
+// than the link itself. Closing brackets stay if the link opened them, as
+// in https://en.wikipedia.org/wiki/Go_(programming_language).
+func trimURL(u string) string {
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		switch last {
+		case '.', ',', ';', ':', '!', '?', '\'', '"':
+		case ')', ']', '}':
+			open := "([{"[strings.IndexByte(")]}", last)]
+			if strings.Count(u, string(open)) >= strings.Count(u, string(last)) {
+				return u
+			}
+		default:
+			return u
+		}
+		u = u[:len(u)-1]
+	}
+	return u
+}
+
+// linkPreviews returns the cached previews for the links in a chirp body.
+// Links that haven't been fetched yet, or had no metadata, are left out.
+func (cfg *apiConfig) linkPreviews(ctx context.Context, body string) []linkPreviewResponse {
+	return previewsFor(body, cfg.loadLinkPreviews(ctx, chirpURLs(body)))
+}
+
+// loadLinkPreviews returns the cached previews for urls, keyed by URL
+func (cfg *apiConfig) loadLinkPreviews(ctx context.Context, urls []string) map[string]database.LinkPreview {
+	if len(urls) == 0 || cfg.db == nil {
+		return nil
+	}
+
+	rows, err := cfg.db.GetLinkPreviews(ctx, urls)
+	if err != nil {
+		log.Printf("failed to load link previews: %v", err)
+		return nil
+	}
+	byURL := make(map[string]database.LinkPreview, len(rows))
+	for _, row := range rows {
+		byURL[row.Url] = row
+	}
+	return byURL
+}
+
+// previewsFor picks the previews for the links in a chirp body out of byURL
+func previewsFor(body string, byURL map[string]database.LinkPreview) []linkPreviewResponse {
+	var previews []linkPreviewResponse
+	for _, u := range chirpURLs(body) {
+		row, ok := byURL[u]
+		if !ok || row.Title == "" {
+			continue
+		}
+		previews = append(previews, linkPreviewResponse{
+			URL:         row.Url,
+			Title:       row.Title,
+			Description: row.Description,
+			Image:       row.ImageUrl,
+		})
+	}
+	return previews
+}
+
+// jobKindLinkPreviews is the background job that fetches link previews
+const jobKindLinkPreviews = "link_previews"
+
+// linkPreviewJob is the payload of a link preview job
+type linkPreviewJob struct {
+	URLs []string `json:"urls"`
+}
+
+// fetchLinkPreviews fetches and caches previews for any of the job's links
+// that aren't cached or have gone stale
+func (cfg *apiConfig) fetchLinkPreviews(ctx context.Context, payload json.RawMessage) error {
+	var job linkPreviewJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return retry.Permanent(err)
+	}
+
+	cached, err := cfg.db.GetLinkPreviews(ctx, job.URLs)
+	if err != nil {
+		return err
+	}
+	fresh := map[string]bool{}
+	for _, row := range cached {
+		if time.Since(row.FetchedAt) < linkPreviewTTL {
+			fresh[row.Url] = true
+		}
+	}
+
+	for _, u := range job.URLs {
+		if fresh[u] {
+			continue
+		}
+		// Failed fetches are cached too, as empty previews, so a broken link
+		// isn't fetched again for every chirp that mentions it
+		preview, err := cfg.previews.Fetch(ctx, u)
+		if err != nil {
+			log.Printf("failed to fetch link preview for %s: %v", u, err)
+		}
+		err = cfg.db.UpsertLinkPreview(ctx, database.UpsertLinkPreviewParams{
+			Url:         u,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageUrl:    preview.ImageURL,
+			FetchedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChirpURLs(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"see https://example.com.", []string{"https://example.com"}},
+		{"(via https://example.com/a), https://example.com/a!", []string{"https://example.com/a"}},
+		{"https://en.wikipedia.org/wiki/Go_(programming_language)", []string{"https://en.wikipedia.org/wiki/Go_(programming_language)"}},
+		{"\"http://example.com/?q=1\"", []string{"http://example.com/?q=1"}},
+		{"no links here", nil},
+	}
+	for _, tt := range tests {
+		if got := chirpURLs(tt.body); !slices.Equal(got, tt.want) {
+			t.Errorf("chirpURLs(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -1,28 +1,82 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
+	"log"
+	"net"
 	"net/http"
+	"net/mail"
 	"os"
+	"os/signal"
+	"regexp"
 	"strings"
 	"sync/atomic"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
+	"github.com/hydeh3r3/chirpy/internal/cache"
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/cursor"
 	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/jobs"
+	"github.com/hydeh3r3/chirpy/internal/linkpreview"
+	"github.com/hydeh3r3/chirpy/internal/metrics"
+	"github.com/hydeh3r3/chirpy/internal/moderation"
+	"github.com/hydeh3r3/chirpy/internal/partitions"
+	"github.com/hydeh3r3/chirpy/internal/request"
+	"github.com/hydeh3r3/chirpy/internal/retry"
+	"github.com/hydeh3r3/chirpy/internal/store"
+	"github.com/hydeh3r3/chirpy/internal/tracing"
 
 	"github.com/google/uuid"
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // apiConfig holds server state and metrics
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	db             *database.Queries
+	metrics        *metrics.Registry
+	db             *database.Queries // nil unless the driver is Postgres
+	store          store.Store
+	cache          *store.Cached   // nil unless caching is on
+	idempotency    idempotencyKeys // nil unless the driver is Postgres
+	views          chirpViews      // nil unless the driver is Postgres
+	polls          chirpPolls      // nil unless the driver is Postgres
+	reviews        chirpReviews    // nil unless the driver is Postgres
+	conn           *sql.DB
 	platform       string
+	previews       *linkpreview.Fetcher
+	jobs           *jobs.Queue
+	emailDomain    string
+	emailSecret    string
+	cursors        *cursor.Codec
+	requestTimeout time.Duration
+	strikes        config.Strikes
+	reports        config.Reports
+	moderation     config.Moderation
+	web            config.Web
+	jsonCase       string
+	adminTokens    map[string]string                   // admin name to token
+	tenants        *cache.LRU[string, database.Tenant] // resolved tenants by slug or host
+	classifier     *moderation.Classifier
+	started        atomic.Bool // set once main has finished starting up
+	migrated       atomic.Bool // set once every migration is known to be applied
+}
+
+// dbConnectPolicy retries the initial database connection for about a minute
+var dbConnectPolicy = retry.Policy{
+	Name:        "db_connect",
+	MaxAttempts: 10,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    15 * time.Second,
+	Multiplier:  2,
+	Jitter:      0.2,
 }
 
 // chirpRequest represents the incoming JSON payload
@@ -32,16 +86,30 @@ type chirpRequest struct {
 
 // chirpResponse represents the chirp data response
 type chirpResponse struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    string    `json:"user_id"`
+	ID           string                `json:"id"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+	Body         string                `json:"body"`
+	UserID       string                `json:"user_id"`
+	RechirpCount int64                 `json:"rechirp_count"`
+	ViewCount    int64                 `json:"view_count"`
+	LinkPreviews []linkPreviewResponse `json:"link_previews"`
+	Poll         *pollResponse         `json:"poll,omitempty"`
+	Warning      string                `json:"warning,omitempty"`
+	Pending      bool                  `json:"pending,omitempty"`
+}
+
+// validateChirpResponse represents the cleaned chirp and its counted length
+type validateChirpResponse struct {
+	Body   string `json:"body"`
+	Length int    `json:"length"`
 }
 
 // errorResponse represents an error message response
 type errorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+	Field string `json:"field,omitempty"`
 }
 
 // userRequest represents the incoming JSON payload
@@ -51,16 +119,34 @@ type userRequest struct {
 
 // userResponse represents the user data response
 type userResponse struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Email     string    `json:"email"`
+	ID             string    `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Email          string    `json:"email"`
+	PostingAddress string    `json:"posting_address,omitempty"`
 }
 
 // chirpCreateRequest represents the incoming JSON payload
 type chirpCreateRequest struct {
-	Body   string    `json:"body"`
-	UserID uuid.UUID `json:"user_id"`
+	Body   string       `json:"body"`
+	UserID uuid.UUID    `json:"user_id"`
+	Poll   *pollRequest `json:"poll"`
+}
+
+// maxChirpLength is the maximum counted length of a chirp
+const maxChirpLength = 140
+
+// urlLength is how many characters any URL counts for, regardless of its real length
+const urlLength = 23
+
+// urlPattern matches http(s) links inside a chirp body
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// chirpLength counts a chirp in Unicode characters, with every URL counted as urlLength
+func chirpLength(body string) int {
+	urls := urlPattern.FindAllString(body, -1)
+	rest := urlPattern.ReplaceAllString(body, "")
+	return utf8.RuneCountInString(rest) + len(urls)*urlLength
 }
 
 // List of profane words to filter
@@ -70,40 +156,73 @@ var profaneWords = []string{
 	"fornax",
 }
 
-// middlewareMetricsInc increments the hit counter for each request
-func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
+// respondWithRequestError writes a 400 naming the invalid field for request
+// parsing errors, and a 500 for anything else
+func respondWithRequestError(w http.ResponseWriter, err error) {
+	var fieldErr *request.FieldError
+	if errors.As(err, &fieldErr) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: fieldErr.Message, Field: fieldErr.Field})
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(errorResponse{Error: "Failed to read request"})
+}
+
+// statusRecorder remembers the status code a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// middlewareMetrics counts each request and its status under route
+func (cfg *apiConfig) middlewareMetrics(route string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		cfg.fileserverHits.Add(1)
-		next.ServeHTTP(w, r)
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		cfg.metrics.Record(route, r.Method, rec.status)
 	})
 }
 
-// metricsHandler returns HTML with the current hit count
+// metricsHandler renders the admin dashboard
 func (cfg *apiConfig) metricsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	html := `<html>
-  <body>
-    <h1>Welcome, Chirpy Admin</h1>
-    <p>Chirpy has been visited %d times!</p>
-  </body>
-</html>`
-	fmt.Fprintf(w, html, cfg.fileserverHits.Load())
-}
 
-// healthzHandler handles health check requests
-func healthzHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	stats, err := cfg.buildAdminStats(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to build stats"})
 		return
 	}
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+	templates.ExecuteTemplate(w, "metrics.html", stats)
 }
 
 // validateChirpHandler handles chirp validation and cleaning
@@ -113,48 +232,31 @@ func validateChirpHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Read the request body
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to read request"})
-		return
-	}
-
 	// Parse the JSON request
 	var chirp chirpRequest
-	err = json.Unmarshal(body, &chirp)
+	err := request.DecodeJSON(r, &chirp, request.DefaultMaxBodyBytes)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse{Error: "Invalid JSON"})
+		respondWithRequestError(w, err)
 		return
 	}
 
 	// Validate chirp length
-	if len(chirp.Body) > 140 {
+	length := chirpLength(chirp.Body)
+	if length > maxChirpLength {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp is too long"})
 		return
 	}
 
 	// Clean the chirp text
-	words := strings.Split(chirp.Body, " ")
-	for i, word := range words {
-		wordLower := strings.ToLower(word)
-		for _, profane := range profaneWords {
-			if wordLower == profane {
-				words[i] = "****"
-				break
-			}
-		}
-	}
-	cleanedChirp := strings.Join(words, " ")
+	cleanedChirp := cleanChirp(chirp.Body)
 
 	// Return cleaned chirp
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(chirpResponse{
-		Body: cleanedChirp,
+	json.NewEncoder(w).Encode(validateChirpResponse{
+		Body:   cleanedChirp,
+		Length: length,
 	})
 }
 
@@ -166,29 +268,19 @@ func (cfg *apiConfig) createUserHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Read and parse request body
-	body, err := io.ReadAll(r.Body)
+	var req userRequest
+	err := request.DecodeJSON(r, &req, request.DefaultMaxBodyBytes)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to read request"})
+		respondWithRequestError(w, err)
 		return
 	}
 
-	var req userRequest
-	err = json.Unmarshal(body, &req)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse{Error: "Invalid JSON"})
+	user, err := cfg.createUser(r.Context(), tenantID(r.Context()), req.Email)
+	var fieldErr *request.FieldError
+	if errors.As(err, &fieldErr) {
+		respondWithRequestError(w, err)
 		return
 	}
-
-	// Create user in database
-	now := time.Now().UTC()
-	user, err := cfg.db.CreateUser(r.Context(), database.CreateUserParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		Email:     req.Email,
-	})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to create user"})
@@ -199,45 +291,62 @@ func (cfg *apiConfig) createUserHandler(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(userResponse{
-		ID:        user.ID.String(),
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		Email:     user.Email,
+		ID:             user.ID.String(),
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+		Email:          user.Email,
+		PostingAddress: cfg.postingAddress(user),
 	})
 }
 
-// createChirpHandler handles chirp creation requests
-func (cfg *apiConfig) createChirpHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
+// maxEmailLength is the longest email address a user can sign up with
+const maxEmailLength = 254
 
-	// Read and parse request body
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to read request"})
-		return
+// validateEmail trims an email address and checks it is a bare address,
+// such as a@example.com
+func validateEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > maxEmailLength {
+		return "", &request.FieldError{Field: "email", Message: "must be a valid email address"}
 	}
+	return email, nil
+}
 
-	var req chirpCreateRequest
-	err = json.Unmarshal(body, &req)
+// createUser validates an email address and stores a new user with it in
+// a tenant. An invalid address is a *request.FieldError. The JSON handler
+// and the gRPC service both sign users up through it.
+func (cfg *apiConfig) createUser(ctx context.Context, tenantID uuid.UUID, email string) (database.User, error) {
+	email, err := validateEmail(email)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse{Error: "Invalid JSON"})
-		return
+		return database.User{}, err
 	}
+	now := time.Now().UTC()
+	return cfg.store.CreateUser(ctx, database.CreateUserParams{
+		ID:            uuid.New(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Email:         email,
+		PostingSecret: store.NewPostingSecret(),
+		TenantID:      tenantID,
+	})
+}
 
-	// Validate chirp length
-	if len(req.Body) > 140 {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp is too long"})
-		return
-	}
+// errChirpTooLong is returned when a chirp exceeds maxChirpLength
+var errChirpTooLong = errors.New("chirp is too long")
 
-	// Clean the chirp text
-	words := strings.Split(req.Body, " ")
+// errDuplicateChirp is returned when a user repeats a chirp within duplicateChirpWindow
+var errDuplicateChirp = errors.New("duplicate chirp")
+
+// errUnknownAuthor is returned when a chirp's author isn't a user of its tenant
+var errUnknownAuthor = errors.New("unknown author")
+
+// duplicateChirpWindow is how long an identical chirp from the same user is rejected
+const duplicateChirpWindow = 2 * time.Minute
+
+// cleanChirp replaces profane words in a chirp body
+func cleanChirp(body string) string {
+	words := strings.Split(body, " ")
 	for i, word := range words {
 		wordLower := strings.ToLower(word)
 		for _, profane := range profaneWords {
@@ -247,20 +356,155 @@ func (cfg *apiConfig) createChirpHandler(w http.ResponseWriter, r *http.Request)
 			}
 		}
 	}
-	cleanedChirp := strings.Join(words, " ")
+	return strings.Join(words, " ")
+}
+
+// createChirp validates, cleans and stores a chirp for a user of tenantID, and starts
+// fetching previews for any links in it. poll, if not nil, is attached to
+// the chirp. Users who are suspended or in a posting cooldown are turned
+// away. Chirps the classifier flags are stored but held for review, which
+// is reported as pending.
+func (cfg *apiConfig) createChirp(ctx context.Context, tenantID, userID uuid.UUID, body string, poll *newPoll) (database.Chirp, bool, error) {
+	_, err := cfg.store.GetUser(ctx, database.GetUserParams{ID: userID, TenantID: tenantID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Chirp{}, false, errUnknownAuthor
+	}
+	if err != nil {
+		return database.Chirp{}, false, err
+	}
+	if err := cfg.checkCanPost(ctx, userID); err != nil {
+		return database.Chirp{}, false, err
+	}
+	if chirpLength(body) > maxChirpLength {
+		return database.Chirp{}, false, errChirpTooLong
+	}
+	cleaned := cleanChirp(body)
 
-	// Create chirp in database
+	// Guard against accidental double posts
 	now := time.Now().UTC()
-	chirp, err := cfg.db.CreateChirp(r.Context(), database.CreateChirpParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		Body:      cleanedChirp,
-		UserID:    req.UserID,
+	_, err = cfg.store.GetRecentDuplicateChirp(ctx, database.GetRecentDuplicateChirpParams{
+		UserID:    userID,
+		Body:      cleaned,
+		CreatedAt: now.Add(-duplicateChirpWindow),
+	})
+	if err == nil {
+		return database.Chirp{}, false, errDuplicateChirp
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.Chirp{}, false, err
+	}
+
+	// The classifier sees the chirp as written, before the word filter
+	id := uuid.New()
+	details, pending := cfg.moderate(ctx, id, body)
+
+	// The chirp, its hold and its poll are stored together or not at all.
+	// Holds and polls only exist with Postgres.
+	var chirp database.Chirp
+	err = cfg.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		chirp, err = tx.CreateChirp(ctx, database.CreateChirpParams{
+			ID:        id,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Body:      cleaned,
+			UserID:    userID,
+			TenantID:  tenantID,
+		})
+		if err != nil {
+			return err
+		}
+		q := store.PostgresQueries(tx)
+		if pending {
+			if err := holdChirp(ctx, q, chirp.ID, holdModeration, details); err != nil {
+				return err
+			}
+		}
+		if poll != nil {
+			return q.CreatePoll(ctx, database.CreatePollParams{
+				ChirpID:   chirp.ID,
+				Options:   poll.options,
+				ClosesAt:  now.Add(poll.duration),
+				CreatedAt: now,
+			})
+		}
+		return nil
 	})
 	if err != nil {
+		return database.Chirp{}, false, err
+	}
+	if err := cfg.snapshotChirpAuthor(ctx, chirp); err != nil {
+		log.Printf("failed to snapshot author of chirp %s: %v", chirp.ID, err)
+	}
+
+	// Fetch previews for any links in the background
+	if urls := chirpURLs(chirp.Body); len(urls) > 0 && cfg.jobs != nil {
+		err = cfg.jobs.Enqueue(ctx, chirp.TenantID, jobKindLinkPreviews, linkPreviewJob{URLs: urls})
+		if err != nil {
+			log.Printf("failed to enqueue link previews for chirp %s: %v", chirp.ID, err)
+		}
+	}
+	return chirp, pending, nil
+}
+
+// respondWithCreateChirpError maps errors from createChirp to responses
+func respondWithCreateChirpError(w http.ResponseWriter, err error) {
+	if respondWithStrikeError(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, errChirpTooLong):
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp is too long"})
+	case errors.Is(err, errUnknownAuthor):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "User not found"})
+	case errors.Is(err, errDuplicateChirp):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(errorResponse{Error: "You just posted this chirp", Code: "duplicate_chirp"})
+	default:
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to create chirp"})
+	}
+}
+
+// createChirpHandler handles chirp creation requests
+func (cfg *apiConfig) createChirpHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Read and parse request body
+	var req chirpCreateRequest
+	err := request.DecodeJSON(r, &req, request.DefaultMaxBodyBytes)
+	if err == nil {
+		err = request.RequireUUID("user_id", req.UserID)
+	}
+	var poll *newPoll
+	if err == nil && req.Poll != nil {
+		// Polls are stored in Postgres only
+		if cfg.db == nil {
+			err = &request.FieldError{Field: "poll", Message: "is not available on this server"}
+		} else {
+			poll, err = parsePoll(req.Poll)
+		}
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Validate, clean and store the chirp
+	chirp, pending, err := cfg.createChirp(r.Context(), tenantID(r.Context()), req.UserID, req.Body, poll)
+	if err != nil {
+		respondWithCreateChirpError(w, err)
+		return
+	}
+	polls, err := cfg.loadPolls(r.Context(), []database.Chirp{chirp})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get poll"})
 		return
 	}
 
@@ -268,101 +512,348 @@ func (cfg *apiConfig) createChirpHandler(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(chirpResponse{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.String(),
+		ID:           chirp.ID.String(),
+		CreatedAt:    chirp.CreatedAt,
+		UpdatedAt:    chirp.UpdatedAt,
+		Body:         chirp.Body,
+		UserID:       chirp.UserID.String(),
+		LinkPreviews: cfg.linkPreviews(r.Context(), chirp.Body),
+		Poll:         polls[chirp.ID],
+		Warning:      cfg.strikeWarning(r.Context(), chirp.UserID),
+		Pending:      pending,
 	})
 }
 
-// resetHandler resets the hit counter and deletes all users
+// chirpToResponse builds the full response for a stored chirp, including its
+// rechirp and view counts, link previews and poll
+func (cfg *apiConfig) chirpToResponse(ctx context.Context, chirp database.Chirp) (chirpResponse, error) {
+	count, err := cfg.store.CountRechirps(ctx, chirp.ID)
+	if err != nil {
+		return chirpResponse{}, err
+	}
+	views, err := cfg.viewCount(ctx, chirp.ID)
+	if err != nil {
+		return chirpResponse{}, err
+	}
+	polls, err := cfg.loadPolls(ctx, []database.Chirp{chirp})
+	if err != nil {
+		return chirpResponse{}, err
+	}
+	return chirpResponse{
+		ID:           chirp.ID.String(),
+		CreatedAt:    chirp.CreatedAt,
+		UpdatedAt:    chirp.UpdatedAt,
+		Body:         chirp.Body,
+		UserID:       chirp.UserID.String(),
+		RechirpCount: count,
+		ViewCount:    views,
+		LinkPreviews: cfg.linkPreviews(ctx, chirp.Body),
+		Poll:         polls[chirp.ID],
+	}, nil
+}
+
+// getChirpHandler returns a single chirp, honoring If-None-Match
+func (cfg *apiConfig) getChirpHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	chirpID, err := request.ParseUUIDParam(r, "chirpID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+	schema := r.URL.Query().Get("schema")
+	if schema != "" && schema != chirpSchemaV1 {
+		respondWithRequestError(w, &request.FieldError{Field: "schema", Message: "must be v1"})
+		return
+	}
+
+	chirp, err := cfg.getVisibleChirp(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirp"})
+		return
+	}
+	if schema == chirpSchemaV1 {
+		cfg.respondWithChirpV1(w, r, chirp)
+		return
+	}
+
+	resp, err := cfg.chirpToResponse(r.Context(), chirp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirp"})
+		return
+	}
+
+	// Rechirps, views, previews and poll votes change the response without
+	// touching updated_at
+	parts := []int64{resp.RechirpCount, resp.ViewCount, int64(len(resp.LinkPreviews))}
+	etag := weakETag(chirp.UpdatedAt, append(parts, pollETagParts(resp.Poll)...)...)
+	if checkNotModified(w, r, etag) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// resetHandler deletes all users, and in dev mode also clears the metrics
 func (cfg *apiConfig) resetHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Check if we're in dev mode
-	if cfg.platform != "dev" {
+	// Check if we're in dev or demo mode
+	if cfg.platform != config.PlatformDev && cfg.platform != config.PlatformDemo {
 		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(errorResponse{Error: "Reset endpoint only available in dev mode"})
+		json.NewEncoder(w).Encode(errorResponse{Error: "Reset endpoint only available in dev and demo mode"})
 		return
 	}
 
-	// Reset hit counter
-	cfg.fileserverHits.Store(0)
-
 	// Delete all users
-	err := cfg.db.DeleteAllUsers(r.Context())
+	err := cfg.store.DeleteAllUsers(r.Context(), tenantID(r.Context()))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to delete users"})
 		return
 	}
 
+	// Counters are only cleared in dev, so a public demo keeps its history
+	details := "deleted all users"
+	if cfg.platform == config.PlatformDev {
+		cfg.metrics.Reset()
+		retry.Reset()
+		if cfg.cache != nil {
+			cfg.cache.ResetStats()
+		}
+		details = "cleared metrics and deleted all users"
+	}
+	cfg.recordAudit(r, auditActionReset, "users", details)
+
 	w.WriteHeader(http.StatusOK)
 }
 
 func main() {
-	// Load .env file
-	err := godotenv.Load()
+	// Load and validate configuration
+	cfg, err := config.Load()
 	if err != nil {
-		panic(err)
+		log.Fatalf("invalid configuration:\n%v", err)
 	}
 
-	// Get environment variables
-	dbURL := os.Getenv("DB_URL")
-	if dbURL == "" {
-		panic("DB_URL environment variable is not set")
+	// Stop background work and the server on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Export traces if a collector is configured
+	var shutdownTracing func(context.Context) error
+	if cfg.Tracing.Enabled() {
+		shutdownTracing, err = tracing.Setup(ctx, cfg.Tracing.ServiceName)
+		if err != nil {
+			log.Fatalf("setting up tracing: %v", err)
+		}
+		log.Printf("exporting traces to %s", cfg.Tracing.Endpoint)
 	}
-	platform := os.Getenv("PLATFORM")
-	if platform == "" {
-		platform = "prod" // Default to prod for safety
+
+	// Open the configured storage
+	var st store.Store
+	var conn *sql.DB
+	var dbQueries *database.Queries
+	switch {
+	case cfg.Platform == config.PlatformDemo:
+		// Demo mode keeps users and chirps in memory and needs no database
+		log.Println("demo mode: data is kept in memory and lost on exit")
+		st = store.NewMemory()
+	case cfg.DBDriver == config.DriverSQLite:
+		// SQLite only backs the store; Postgres-only features stay off
+		db, err := store.OpenSQLite(cfg.DBURL)
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		if err := store.MigrateSQLite(ctx, db); err != nil {
+			log.Fatalf("migrating sqlite database: %v", err)
+		}
+		conn = db
+		st = store.NewSQLite(db)
+	default:
+		// Open database connection; it is first used once the server is
+		// listening, so the probes can answer while it comes up
+		db, err := sql.Open("postgres", cfg.DBURL)
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		// Create database queries, traced like the store's
+		pg := store.NewPostgres(db)
+		dbQueries = pg.Queries
+		conn = db
+		st = pg
 	}
 
-	// Open database connection
-	db, err := sql.Open("postgres", dbURL)
-	if err != nil {
-		panic(err)
+	// Create API config
+	apiCfg := newAPIConfig(cfg, st)
+	apiCfg.conn = conn
+	if dbQueries != nil {
+		apiCfg.db = dbQueries
+		apiCfg.idempotency = dbQueries
+		apiCfg.views = dbQueries
+		apiCfg.polls = dbQueries
+		apiCfg.reviews = dbQueries
+		apiCfg.jobs = jobs.New(dbQueries, cfg.JobWorkers)
+
+		apiCfg.jobs.Register(jobKindLinkPreviews, apiCfg.fetchLinkPreviews)
+
+		if cfg.Moderation.Enabled() {
+			apiCfg.classifier = moderation.New(cfg.Moderation.URL, cfg.Moderation.Secret, cfg.Moderation.Timeout)
+		}
+	} else if cfg.Moderation.Enabled() {
+		// Held chirps are kept in Postgres
+		log.Println("MODERATION_URL is ignored without Postgres")
 	}
-	defer db.Close()
 
-	// Create database queries
-	dbQueries := database.New(db)
+	// Create a new http.Server with the API as handler
+	server := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           apiCfg.routes(),
+		ReadHeaderTimeout: cfg.Timeouts.Read,
+		ReadTimeout:       cfg.Timeouts.Read,
+		WriteTimeout:      cfg.Timeouts.Write,
+		IdleTimeout:       cfg.Timeouts.Idle,
+	}
+	servers := []*http.Server{server}
+
+	manager := autocertManager(cfg.TLS)
+	if cfg.TLS.Enabled() {
+		// Serve the API over HTTPS; the plain listener only redirects (and
+		// answers ACME HTTP-01 challenges when using autocert)
+		server.Addr = cfg.TLS.HTTPSAddr
+		server.Handler = middlewareHSTS(cfg.TLS, server.Handler)
+		server.TLSConfig = serverTLSConfig(manager)
+
+		redirect := redirectToHTTPS(cfg.TLS.HTTPSAddr)
+		if manager != nil {
+			redirect = manager.HTTPHandler(redirect)
+		}
+		redirectServer := &http.Server{
+			Addr:              cfg.Addr(),
+			Handler:           redirect,
+			ReadHeaderTimeout: cfg.Timeouts.Read,
+			ReadTimeout:       cfg.Timeouts.Read,
+			WriteTimeout:      cfg.Timeouts.Write,
+			IdleTimeout:       cfg.Timeouts.Idle,
+		}
+		if manager != nil {
+			redirectServer.Addr = ":80"
+		}
+		servers = append(servers, redirectServer)
+	}
 
-	// Create API config
-	apiCfg := &apiConfig{
-		db:       dbQueries,
-		platform: platform,
+	// Start the servers
+	for _, srv := range servers {
+		go func(srv *http.Server) {
+			var err error
+			if srv.TLSConfig != nil {
+				// Cert and key come from TLSConfig when using autocert
+				err = srv.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+			} else {
+				err = srv.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				panic(err)
+			}
+		}(srv)
+	}
+
+	// Serve the gRPC API on its own listener, over TLS when HTTPS is on
+	var grpcServer *grpc.Server
+	if cfg.GRPCAddr != "" {
+		var opts []grpc.ServerOption
+		if cfg.TLS.Enabled() {
+			tlsConfig, err := grpcTLSConfig(cfg.TLS, manager)
+			if err != nil {
+				log.Fatalf("loading the gRPC certificate: %v", err)
+			}
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		lis, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			log.Fatalf("listening for gRPC: %v", err)
+		}
+		grpcServer = apiCfg.newGRPCServer(opts...)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				panic(err)
+			}
+		}()
+		log.Printf("serving gRPC on %s", cfg.GRPCAddr)
 	}
 
-	// Create a new ServeMux instance
-	mux := http.NewServeMux()
+	// Finish starting up while the servers answer health probes
+	if dbQueries != nil {
+		// Wait for the database to accept connections, e.g. while it starts up
+		err = dbConnectPolicy.Do(ctx, conn.PingContext)
+		if err != nil {
+			panic(err)
+		}
 
-	// Add API endpoints
-	mux.HandleFunc("/api/healthz", healthzHandler)
-	mux.HandleFunc("/api/users", apiCfg.createUserHandler)
-	mux.HandleFunc("/api/chirps", apiCfg.createChirpHandler)
+		// Keep monthly chirp partitions created ahead of time
+		go partitions.Run(ctx, conn, 24*time.Hour)
 
-	// Add admin endpoints
-	mux.HandleFunc("/admin/metrics", apiCfg.metricsHandler)
-	mux.HandleFunc("/admin/reset", apiCfg.resetHandler)
+		// Start the background job workers
+		apiCfg.jobs.Start()
 
-	// Add fileserver handler with /app prefix and metrics middleware
-	fileServer := http.FileServer(http.Dir("."))
-	handler := http.StripPrefix("/app/", fileServer)
-	mux.Handle("/app/", apiCfg.middlewareMetricsInc(handler))
+		// Forget idempotency keys once they expire
+		go apiCfg.purgeIdempotencyKeys(ctx)
 
-	// Create a new http.Server with the mux as handler
-	server := &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+		// Add recorded views to the view counts
+		go apiCfg.rollupChirpViews(ctx)
 	}
-
-	// Start the server
-	err = server.ListenAndServe()
-	if err != nil {
-		panic(err)
+	apiCfg.started.Store(true)
+	log.Println("startup complete")
+
+	// Wait for a shutdown signal, then drain requests and running jobs
+	<-ctx.Done()
+	log.Println("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	for _, srv := range servers {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("server shutdown: %v", err)
+		}
+	}
+	if grpcServer != nil {
+		// Cut off calls still running when the shutdown timeout is up
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
+	if apiCfg.jobs != nil {
+		if err := apiCfg.jobs.Shutdown(shutdownCtx); err != nil {
+			log.Printf("job queue shutdown: %v", err)
+		}
+	}
+	if shutdownTracing != nil {
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			log.Printf("tracing shutdown: %v", err)
+		}
 	}
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+)
+
+func TestRouteMetrics(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	srv.do(http.MethodPost, "/api/validate_chirp", `{"body":"hello"}`)
+	srv.do(http.MethodPost, "/api/validate_chirp", `{"body":`)
+	srv.do(http.MethodGet, "/api/chirps/nope", "")
+
+	rec := srv.do(http.MethodGet, "/admin/stats", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	routes := map[string]routeStats{}
+	for _, r := range decode[adminStats](t, rec).Routes {
+		routes[r.Method+" "+r.Route] = r
+	}
+	if got := routes["POST /api/validate_chirp"]; got.Requests != 2 || got.ClientErrors != 1 {
+		t.Errorf("validate_chirp = %+v, want 2 requests and 1 client error", got)
+	}
+	// Routes are counted by pattern, not by path
+	if got := routes["GET /api/chirps/{chirpID}"]; got.Requests != 1 || got.ClientErrors != 1 {
+		t.Errorf("get chirp = %+v, want 1 request and 1 client error", got)
+	}
+
+	rec = srv.do(http.MethodGet, "/metrics", "")
+	want := `chirpy_http_requests_total{route="/api/validate_chirp",method="POST"} 2`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("/metrics is missing %q:\n%s", want, rec.Body)
+	}
+}
+
+func TestResetClearsMetricsOnlyInDev(t *testing.T) {
+	for _, tt := range []struct {
+		platform  string
+		wantClear bool
+	}{
+		{config.PlatformDev, true},
+		{config.PlatformDemo, false},
+	} {
+		t.Run(tt.platform, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Platform = tt.platform
+			srv := newTestServer(t, cfg)
+			srv.do(http.MethodGet, "/healthz", "")
+
+			if rec := srv.do(http.MethodPost, "/admin/reset", ""); rec.Code != http.StatusOK {
+				t.Fatalf("reset status = %d (%s)", rec.Code, rec.Body)
+			}
+			stats := decode[adminStats](t, srv.do(http.MethodGet, "/admin/stats", ""))
+			cleared := true
+			for _, r := range stats.Routes {
+				if r.Route == "/healthz" {
+					cleared = false
+				}
+			}
+			if cleared != tt.wantClear {
+				t.Errorf("cleared = %v, want %v", cleared, tt.wantClear)
+			}
+		})
+	}
+}
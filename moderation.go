@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+	"github.com/hydeh3r3/chirpy/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Moderation queue page sizes
+const (
+	moderationDefaultLimit = 50
+	moderationMaxLimit     = 200
+)
+
+// Audited moderation actions
+const (
+	auditActionApproveChirp = "approve_chirp"
+	auditActionRejectChirp  = "reject_chirp"
+)
+
+// errChirpNotPending is returned when a chirp isn't held for moderation,
+// for instance because another admin decided on it first
+var errChirpNotPending = errors.New("chirp is not pending review")
+
+// pendingChirpResponse is a chirp held by the classifier, waiting for review
+type pendingChirpResponse struct {
+	ChirpID   string    `json:"chirp_id"`
+	UserID    string    `json:"user_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	HeldAt    time.Time `json:"held_at"`
+	Details   string    `json:"details"`
+}
+
+// pendingChirpsResponse represents a page of the moderation queue
+type pendingChirpsResponse struct {
+	Chirps     []pendingChirpResponse `json:"chirps"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+// moderate asks the classifier about a new chirp before it is published.
+// It reports whether the chirp should be held, and why. When the classifier
+// can't be reached the chirp is published or held depending on
+// MODERATION_FAIL_OPEN.
+func (cfg *apiConfig) moderate(ctx context.Context, chirpID uuid.UUID, text string) (string, bool) {
+	if cfg.classifier == nil {
+		return "", false
+	}
+
+	ctx, span := tracing.Start(ctx, "moderation.classify", trace.WithAttributes(attribute.String("chirpy.chirp_id", chirpID.String())))
+	verdict, err := cfg.classifier.Classify(ctx, chirpID.String(), text)
+	if err == nil {
+		span.SetAttributes(attribute.Float64("chirpy.moderation.score", verdict.Score))
+	}
+	tracing.End(span, err)
+	if err != nil {
+		log.Printf("failed to classify chirp %s: %v", chirpID, err)
+		if cfg.moderation.FailOpen {
+			return "", false
+		}
+		return "classifier unavailable", true
+	}
+	if verdict.Score < cfg.moderation.Threshold {
+		return "", false
+	}
+	details := fmt.Sprintf("score %.2f", verdict.Score)
+	if len(verdict.Categories) > 0 {
+		details += ": " + strings.Join(verdict.Categories, ", ")
+	}
+	return details, true
+}
+
+// moderationQueueHandler lists chirps held by the classifier, oldest first,
+// paginated with cursor and limit
+func (cfg *apiConfig) moderationQueueHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	params := database.ListChirpHoldsParams{Reason: holdModeration, TenantID: tenantID(r.Context())}
+	limit, err := request.ParseInt(r, "limit", moderationDefaultLimit, 1, moderationMaxLimit)
+	if err == nil && query.Get("cursor") != "" {
+		params.AfterCreatedAt, params.AfterID, err = cfg.decodePageCursor(query.Get("cursor"))
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+	params.RowLimit = int32(limit)
+
+	holds, err := cfg.reviews.ListChirpHolds(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list pending chirps"})
+		return
+	}
+
+	var ids []uuid.UUID
+	for _, hold := range holds {
+		ids = append(ids, hold.ChirpID)
+	}
+	chirps, err := cfg.store.GetChirpsByIDs(r.Context(), database.GetChirpsByIDsParams{
+		TenantID: tenantID(r.Context()),
+		Ids:      ids,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list pending chirps"})
+		return
+	}
+	byID := make(map[uuid.UUID]database.Chirp, len(chirps))
+	for _, chirp := range chirps {
+		byID[chirp.ID] = chirp
+	}
+
+	resp := pendingChirpsResponse{Chirps: []pendingChirpResponse{}}
+	for _, hold := range holds {
+		// Skip chirps deleted since the queue was read
+		chirp, ok := byID[hold.ChirpID]
+		if !ok {
+			continue
+		}
+		resp.Chirps = append(resp.Chirps, pendingChirpResponse{
+			ChirpID:   chirp.ID.String(),
+			UserID:    chirp.UserID.String(),
+			Body:      chirp.Body,
+			CreatedAt: chirp.CreatedAt,
+			HeldAt:    hold.CreatedAt,
+			Details:   hold.Details,
+		})
+	}
+	if len(holds) == limit {
+		last := holds[len(holds)-1]
+		resp.NextCursor, err = cfg.cursors.Encode(pageCursor{CreatedAt: last.CreatedAt, ID: last.ChirpID})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list pending chirps"})
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// approveChirpHandler publishes a chirp held by the classifier
+func (cfg *apiConfig) approveChirpHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.reviewPendingChirp(w, r, true)
+}
+
+// rejectChirpHandler keeps a chirp held by the classifier hidden for good
+// and gives its author a strike
+func (cfg *apiConfig) rejectChirpHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.reviewPendingChirp(w, r, false)
+}
+
+// reviewPendingChirp approves or rejects a chirp in the moderation queue
+func (cfg *apiConfig) reviewPendingChirp(w http.ResponseWriter, r *http.Request, approve bool) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	chirpID, err := request.ParseUUIDParam(r, "chirpID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	chirp, err := cfg.store.GetChirp(r.Context(), database.GetChirpParams{ID: chirpID, TenantID: tenantID(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirp"})
+		return
+	}
+
+	hold, err := cfg.reviews.GetChirpHold(r.Context(), database.GetChirpHoldParams{ChirpID: chirpID, TenantID: chirp.TenantID})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && hold.Reason != holdModeration) {
+		err = errChirpNotPending
+	}
+	if err == nil {
+		// Both decisions only change a hold still pending moderation, so
+		// when two admins decide at once only the first one counts
+		var changed int64
+		if approve {
+			changed, err = cfg.reviews.ReleaseChirp(r.Context(), database.ReleaseChirpParams{
+				ChirpID: chirpID,
+				Reason:  holdModeration,
+			})
+		} else {
+			changed, err = cfg.reviews.UpdateChirpHoldReason(r.Context(), database.UpdateChirpHoldReasonParams{
+				NewReason: holdRemoved,
+				ChirpID:   chirpID,
+				Reason:    holdModeration,
+			})
+		}
+		if err == nil && changed == 0 {
+			err = errChirpNotPending
+		}
+	}
+	if errors.Is(err, errChirpNotPending) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp is not pending review", Code: "not_pending"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to review chirp"})
+		return
+	}
+
+	if approve {
+		cfg.recordAudit(r, auditActionApproveChirp, chirpID.String(), hold.Details)
+	} else {
+		cfg.recordAudit(r, auditActionRejectChirp, chirpID.String(), hold.Details)
+		if err := cfg.addStrike(r.Context(), chirp.UserID, strikeRejectedChirp, chirp.ID.String()); err != nil {
+			log.Printf("failed to add strike for chirp %s: %v", chirpID, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
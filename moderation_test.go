@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/moderation"
+
+	"github.com/google/uuid"
+)
+
+func TestModerate(t *testing.T) {
+	classifier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"score":0.85,"categories":["hate","spam"]}`))
+	}))
+	defer classifier.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer down.Close()
+
+	tests := []struct {
+		name        string
+		url         string
+		threshold   float64
+		failOpen    bool
+		wantPending bool
+		wantDetails string
+	}{
+		{"below threshold", classifier.URL, 0.9, true, false, ""},
+		{"at threshold", classifier.URL, 0.85, true, true, "score 0.85: hate, spam"},
+		{"down, fail open", down.URL, 0.8, true, false, ""},
+		{"down, fail closed", down.URL, 0.8, false, true, "classifier unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &apiConfig{
+				moderation: config.Moderation{Threshold: tt.threshold, FailOpen: tt.failOpen},
+				classifier: moderation.New(tt.url, "", time.Second),
+			}
+			details, pending := cfg.moderate(context.Background(), uuid.New(), "hello")
+			if pending != tt.wantPending || details != tt.wantDetails {
+				t.Errorf("moderate = %q, %v, want %q, %v", details, pending, tt.wantDetails, tt.wantPending)
+			}
+		})
+	}
+
+	// Without a classifier everything is published
+	if _, pending := (&apiConfig{}).moderate(context.Background(), uuid.New(), "hello"); pending {
+		t.Error("pending without a classifier")
+	}
+}
+
+func TestReviewPendingChirp(t *testing.T) {
+	srv, reviews := newReviewsTestServer(t, testConfig())
+	author := srv.createUser("author@example.com")
+	hold := func(body, reason string) string {
+		t.Helper()
+		chirp := srv.createChirp(author.ID, body)
+		err := reviews.HoldChirp(context.Background(), database.HoldChirpParams{
+			ChirpID:   uuid.MustParse(chirp.ID),
+			Reason:    reason,
+			Details:   "score 0.9: spam",
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chirp.ID
+	}
+	approved := hold("approve me", holdModeration)
+	rejected := hold("reject me", holdModeration)
+	reported := hold("reported", holdReports)
+	published := srv.createChirp(author.ID, "never held").ID
+
+	if rec := srv.do(http.MethodPost, "/admin/moderation/"+approved+"/approve", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("approve: status = %d, want 204 (%s)", rec.Code, rec.Body)
+	}
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+approved, ""); rec.Code != http.StatusOK {
+		t.Errorf("approved chirp: status = %d, want 200", rec.Code)
+	}
+	if rec := srv.do(http.MethodPost, "/admin/moderation/"+rejected+"/reject", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("reject: status = %d, want 204 (%s)", rec.Code, rec.Body)
+	}
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+rejected, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("rejected chirp: status = %d, want 404", rec.Code)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		want     int
+		wantCode string
+	}{
+		{"approve approved", "/admin/moderation/" + approved + "/approve", http.StatusConflict, "not_pending"},
+		{"reject approved", "/admin/moderation/" + approved + "/reject", http.StatusConflict, "not_pending"},
+		{"approve rejected", "/admin/moderation/" + rejected + "/approve", http.StatusConflict, "not_pending"},
+		{"reject rejected", "/admin/moderation/" + rejected + "/reject", http.StatusConflict, "not_pending"},
+		{"held for reports", "/admin/moderation/" + reported + "/approve", http.StatusConflict, "not_pending"},
+		{"never held", "/admin/moderation/" + published + "/reject", http.StatusConflict, "not_pending"},
+		{"unknown", "/admin/moderation/" + uuid.NewString() + "/approve", http.StatusNotFound, ""},
+		{"bad id", "/admin/moderation/nope/approve", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		rec := srv.do(http.MethodPost, tt.path, "")
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, rec.Code, tt.want, rec.Body)
+		}
+		if tt.wantCode != "" && decode[errorResponse](t, rec).Code != tt.wantCode {
+			t.Errorf("%s: code != %s", tt.name, tt.wantCode)
+		}
+	}
+
+	// An approve and a reject that both find the chirp pending: only one
+	// of them is made
+	contested := hold("contested", holdModeration)
+	var looked sync.WaitGroup
+	looked.Add(2)
+	reviews.afterGetHold = func() {
+		looked.Done()
+		looked.Wait()
+	}
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i, action := range []string{"approve", "reject"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = srv.do(http.MethodPost, "/admin/moderation/"+contested+"/"+action, "").Code
+		}()
+	}
+	wg.Wait()
+	decided := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusNoContent:
+			decided++
+		case http.StatusConflict:
+		default:
+			t.Errorf("concurrent decision: status = %d, want 204 or 409", code)
+		}
+	}
+	if decided != 1 {
+		t.Errorf("%d concurrent decisions were made, want 1", decided)
+	}
+}
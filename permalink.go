@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+)
+
+// chirpSchemaV1 is the schema query parameter for the v1 permalink
+const chirpSchemaV1 = "v1"
+
+// chirpV1 is the v1 permalink representation of a chirp, for archiving and
+// embedding. It is frozen: a change to any field means a new schema
+// version, never an edit here. Counts, previews and polls are left out
+// because they change after posting.
+type chirpV1 struct {
+	Schema    string        `json:"schema"`
+	ID        string        `json:"id"`
+	URL       string        `json:"url"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Body      string        `json:"body"`
+	Author    chirpAuthorV1 `json:"author"`
+}
+
+// chirpAuthorV1 is the author of a chirp as they were when it was posted.
+// CapturedAt is omitted when no snapshot was taken and the author is shown
+// as they are now, which is always the case without Postgres.
+type chirpAuthorV1 struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+}
+
+// snapshotChirpAuthor records the author of a new chirp as they are now
+func (cfg *apiConfig) snapshotChirpAuthor(ctx context.Context, chirp database.Chirp) error {
+	if cfg.db == nil {
+		return nil
+	}
+	return cfg.db.CreateChirpAuthor(ctx, database.CreateChirpAuthorParams{
+		ChirpID:    chirp.ID,
+		CapturedAt: chirp.CreatedAt,
+		UserID:     chirp.UserID,
+	})
+}
+
+// chirpAuthor returns the author snapshot for a chirp, falling back to the
+// author as they are now if there isn't one
+func (cfg *apiConfig) chirpAuthor(ctx context.Context, chirp database.Chirp) (chirpAuthorV1, error) {
+	if cfg.db != nil {
+		snapshot, err := cfg.db.GetChirpAuthor(ctx, chirp.ID)
+		if err == nil {
+			return chirpAuthorV1{
+				ID:         snapshot.UserID.String(),
+				Email:      snapshot.Email,
+				CapturedAt: &snapshot.CapturedAt,
+			}, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return chirpAuthorV1{}, err
+		}
+	}
+
+	user, err := cfg.store.GetUser(ctx, database.GetUserParams{ID: chirp.UserID, TenantID: chirp.TenantID})
+	if err != nil {
+		return chirpAuthorV1{}, err
+	}
+	return chirpAuthorV1{ID: user.ID.String(), Email: user.Email}, nil
+}
+
+// respondWithChirpV1 writes the v1 permalink representation of a chirp
+func (cfg *apiConfig) respondWithChirpV1(w http.ResponseWriter, r *http.Request, chirp database.Chirp) {
+	author, err := cfg.chirpAuthor(r.Context(), chirp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirp author"})
+		return
+	}
+
+	// Only an edit to the chirp changes this representation
+	if checkNotModified(w, r, weakETag(chirp.UpdatedAt)) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(chirpV1{
+		Schema:    chirpSchemaV1,
+		ID:        chirp.ID.String(),
+		URL:       "/api/chirps/" + chirp.ID.String() + "?schema=" + chirpSchemaV1,
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+		Author:    author,
+	})
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestChirpPermalink(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	user := srv.createUser("author@example.com")
+	chirp := srv.createChirp(user.ID, "hello")
+
+	rec := srv.do(http.MethodGet, "/api/chirps/"+chirp.ID+"?schema=v1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	got := decode[chirpV1](t, rec)
+	if got.Schema != "v1" || got.Body != "hello" || got.URL != "/api/chirps/"+chirp.ID+"?schema=v1" {
+		t.Errorf("permalink = %+v", got)
+	}
+	// Without Postgres there are no snapshots, so the author is shown as they are now
+	if got.Author.ID != user.ID || got.Author.Email != user.Email || got.Author.CapturedAt != nil {
+		t.Errorf("author = %+v", got.Author)
+	}
+
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+chirp.ID+"?schema=v2", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown schema: status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// Poll limits
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	defaultPollDuration = 24 * time.Hour
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// chirpPolls reads polls and records votes in them
+type chirpPolls interface {
+	GetPoll(ctx context.Context, chirpID uuid.UUID) (database.Poll, error)
+	GetPollsByChirpIDs(ctx context.Context, chirpIDs []uuid.UUID) ([]database.Poll, error)
+	GetPollTallies(ctx context.Context, chirpIDs []uuid.UUID) ([]database.GetPollTalliesRow, error)
+	CastPollVote(ctx context.Context, arg database.CastPollVoteParams) (int64, error)
+}
+
+// pollRequest represents a poll in a chirp creation request
+type pollRequest struct {
+	Options         []string `json:"options"`
+	DurationMinutes int      `json:"duration_minutes"`
+}
+
+// newPoll is a validated poll waiting to be stored with its chirp
+type newPoll struct {
+	options  []string
+	duration time.Duration
+}
+
+// pollResponse represents a poll attached to a chirp. Tallies are hidden
+// until the poll closes, and MyVote is only set on the vote response.
+type pollResponse struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+	Closed   bool      `json:"closed"`
+	Tallies  []int64   `json:"tallies,omitempty"`
+	MyVote   *int      `json:"my_vote,omitempty"`
+
+	// votes is the total even when the tallies are hidden, for ETags
+	votes int64
+}
+
+// pollVoteRequest represents the incoming JSON payload
+type pollVoteRequest struct {
+	Option *int `json:"option"`
+}
+
+// parsePoll validates a poll from a chirp creation request
+func parsePoll(req *pollRequest) (*newPoll, error) {
+	if len(req.Options) < minPollOptions || len(req.Options) > maxPollOptions {
+		return nil, &request.FieldError{
+			Field:   "poll.options",
+			Message: fmt.Sprintf("must have between %d and %d options", minPollOptions, maxPollOptions),
+		}
+	}
+
+	seen := map[string]bool{}
+	options := make([]string, len(req.Options))
+	for i, option := range req.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || utf8.RuneCountInString(option) > maxPollOptionLength {
+			return nil, &request.FieldError{
+				Field:   "poll.options",
+				Message: fmt.Sprintf("must each be 1 to %d characters", maxPollOptionLength),
+			}
+		}
+		if seen[strings.ToLower(option)] {
+			return nil, &request.FieldError{Field: "poll.options", Message: "must be distinct"}
+		}
+		seen[strings.ToLower(option)] = true
+		options[i] = option
+	}
+
+	duration := defaultPollDuration
+	if req.DurationMinutes != 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if duration < minPollDuration || duration > maxPollDuration {
+		return nil, &request.FieldError{
+			Field:   "poll.duration_minutes",
+			Message: fmt.Sprintf("must be between %d and %d", int(minPollDuration.Minutes()), int(maxPollDuration.Minutes())),
+		}
+	}
+	return &newPoll{options: options, duration: duration}, nil
+}
+
+// loadPolls returns the polls on chirps keyed by chirp ID. Chirps without
+// a poll are left out, and without Postgres there are no polls at all.
+func (cfg *apiConfig) loadPolls(ctx context.Context, chirps []database.Chirp) (map[uuid.UUID]*pollResponse, error) {
+	if len(chirps) == 0 || cfg.polls == nil {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		ids = append(ids, chirp.ID)
+	}
+
+	polls, err := cfg.polls.GetPollsByChirpIDs(ctx, ids)
+	if err != nil || len(polls) == 0 {
+		return nil, err
+	}
+	pollIDs := make([]uuid.UUID, 0, len(polls))
+	for _, poll := range polls {
+		pollIDs = append(pollIDs, poll.ChirpID)
+	}
+
+	tallies, err := cfg.polls.GetPollTallies(ctx, pollIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	byID := make(map[uuid.UUID]*pollResponse, len(polls))
+	for _, poll := range polls {
+		resp := &pollResponse{
+			Options:  poll.Options,
+			ClosesAt: poll.ClosesAt,
+			Closed:   !now.Before(poll.ClosesAt),
+		}
+		if resp.Closed {
+			resp.Tallies = make([]int64, len(poll.Options))
+		}
+		byID[poll.ChirpID] = resp
+	}
+	for _, row := range tallies {
+		resp := byID[row.ChirpID]
+		resp.votes += row.Votes
+		if resp.Tallies != nil && int(row.OptionIndex) < len(resp.Tallies) {
+			resp.Tallies[row.OptionIndex] = row.Votes
+		}
+	}
+	return byID, nil
+}
+
+// pollETagParts returns what a poll adds to its chirp's ETag, since votes
+// and closing change the response without touching the chirp
+func pollETagParts(poll *pollResponse) []int64 {
+	if poll == nil {
+		return nil
+	}
+	closed := int64(0)
+	if poll.Closed {
+		closed = 1
+	}
+	return []int64{poll.votes, closed}
+}
+
+// pollVoter identifies a voter for deduplication by client IP, as
+// chirpViewer does for views. A user_id in the body isn't verified, so
+// counting by it would let anyone vote as often as they like.
+func pollVoter(r *http.Request) string {
+	return "ip:" + clientIP(r)
+}
+
+// pollVoteHandler casts a vote in a chirp's poll. Each client IP votes once
+// and can't change its vote; a user_id in the body is ignored.
+func (cfg *apiConfig) pollVoteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	chirpID, err := request.ParseUUIDParam(r, "chirpID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Read and parse request body
+	var req pollVoteRequest
+	err = request.DecodeJSON(r, &req, request.DefaultMaxBodyBytes)
+	if err == nil && req.Option == nil {
+		err = &request.FieldError{Field: "option", Message: "is required"}
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	chirp, err := cfg.getVisibleChirp(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirp"})
+		return
+	}
+
+	poll, err := cfg.polls.GetPoll(r.Context(), chirp.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Poll not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get poll"})
+		return
+	}
+	if *req.Option < 0 || *req.Option >= len(poll.Options) {
+		respondWithRequestError(w, &request.FieldError{
+			Field:   "option",
+			Message: fmt.Sprintf("must be between 0 and %d", len(poll.Options)-1),
+		})
+		return
+	}
+	now := time.Now().UTC()
+	if !now.Before(poll.ClosesAt) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Poll is closed", Code: "poll_closed"})
+		return
+	}
+
+	cast, err := cfg.polls.CastPollVote(r.Context(), database.CastPollVoteParams{
+		ChirpID:     chirp.ID,
+		Voter:       pollVoter(r),
+		OptionIndex: int32(*req.Option),
+		CreatedAt:   now,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to vote"})
+		return
+	}
+	if cast == 0 {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(errorResponse{Error: "You already voted in this poll", Code: "already_voted"})
+		return
+	}
+
+	polls, err := cfg.loadPolls(r.Context(), []database.Chirp{chirp})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get poll"})
+		return
+	}
+
+	// Return the poll with the vote just cast
+	resp := polls[chirp.ID]
+	resp.MyVote = req.Option
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// memoryPolls keeps polls and their votes in memory the way the Postgres
+// queries do
+type memoryPolls struct {
+	mu    sync.Mutex
+	polls map[uuid.UUID]database.Poll
+	votes map[uuid.UUID]map[string]int32 // chirp ID to voter to option
+}
+
+func newMemoryPolls() *memoryPolls {
+	return &memoryPolls{
+		polls: map[uuid.UUID]database.Poll{},
+		votes: map[uuid.UUID]map[string]int32{},
+	}
+}
+
+func (m *memoryPolls) add(chirpID string, options []string, closesAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := uuid.MustParse(chirpID)
+	m.polls[id] = database.Poll{ChirpID: id, Options: options, ClosesAt: closesAt}
+}
+
+func (m *memoryPolls) GetPoll(ctx context.Context, chirpID uuid.UUID) (database.Poll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	poll, ok := m.polls[chirpID]
+	if !ok {
+		return database.Poll{}, sql.ErrNoRows
+	}
+	return poll, nil
+}
+
+func (m *memoryPolls) GetPollsByChirpIDs(ctx context.Context, chirpIDs []uuid.UUID) ([]database.Poll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var polls []database.Poll
+	for _, id := range chirpIDs {
+		if poll, ok := m.polls[id]; ok {
+			polls = append(polls, poll)
+		}
+	}
+	return polls, nil
+}
+
+func (m *memoryPolls) GetPollTallies(ctx context.Context, chirpIDs []uuid.UUID) ([]database.GetPollTalliesRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rows []database.GetPollTalliesRow
+	for _, id := range chirpIDs {
+		counts := map[int32]int64{}
+		for _, option := range m.votes[id] {
+			counts[option]++
+		}
+		for option, votes := range counts {
+			rows = append(rows, database.GetPollTalliesRow{ChirpID: id, OptionIndex: option, Votes: votes})
+		}
+	}
+	return rows, nil
+}
+
+func (m *memoryPolls) CastPollVote(ctx context.Context, arg database.CastPollVoteParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.votes[arg.ChirpID] == nil {
+		m.votes[arg.ChirpID] = map[string]int32{}
+	}
+	if _, ok := m.votes[arg.ChirpID][arg.Voter]; ok {
+		return 0, nil
+	}
+	m.votes[arg.ChirpID][arg.Voter] = arg.OptionIndex
+	return 1, nil
+}
+
+// newPollsTestServer serves the poll routes with polls kept in memory
+func newPollsTestServer(t *testing.T) (*testServer, *memoryPolls) {
+	st := store.NewMemory()
+	cfg := newAPIConfig(testConfig(), st)
+	polls := newMemoryPolls()
+	cfg.polls = polls
+	handler := cfg.mount([]route{
+		{pattern: "/api/users", handler: cfg.createUserHandler},
+		{pattern: "/api/chirps", handler: cfg.createChirpHandler},
+		{pattern: "/api/chirps/{chirpID}", handler: cfg.getChirpHandler},
+		{pattern: "/api/chirps/batch", handler: cfg.chirpBatchHandler},
+		{pattern: "/api/chirps/{chirpID}/poll/vote", handler: cfg.pollVoteHandler},
+	})
+	return &testServer{t: t, handler: handler, store: st}, polls
+}
+
+// vote votes in a chirp's poll from a client address
+func (s *testServer) vote(chirpID, remoteAddr, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirpID+"/poll/vote", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestParsePoll(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          pollRequest
+		wantField    string
+		wantDuration time.Duration
+	}{
+		{"default duration", pollRequest{Options: []string{"yes", "no"}}, "", defaultPollDuration},
+		{"custom duration", pollRequest{Options: []string{"a", "b", "c"}, DurationMinutes: 60}, "", time.Hour},
+		{"one option", pollRequest{Options: []string{"yes"}}, "poll.options", 0},
+		{"five options", pollRequest{Options: []string{"a", "b", "c", "d", "e"}}, "poll.options", 0},
+		{"blank option", pollRequest{Options: []string{"yes", "  "}}, "poll.options", 0},
+		{"long option", pollRequest{Options: []string{"yes", strings.Repeat("n", 26)}}, "poll.options", 0},
+		{"duplicate options", pollRequest{Options: []string{"Yes", "yes"}}, "poll.options", 0},
+		{"too short", pollRequest{Options: []string{"yes", "no"}, DurationMinutes: 1}, "poll.duration_minutes", 0},
+		{"too long", pollRequest{Options: []string{"yes", "no"}, DurationMinutes: 7*24*60 + 1}, "poll.duration_minutes", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll, err := parsePoll(&tt.req)
+			if tt.wantField != "" {
+				var fieldErr *request.FieldError
+				if !errors.As(err, &fieldErr) || fieldErr.Field != tt.wantField {
+					t.Fatalf("err = %v, want a field error for %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if poll.duration != tt.wantDuration {
+				t.Errorf("duration = %v, want %v", poll.duration, tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestPollNeedsPostgres(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	user := srv.createUser("a@example.com")
+
+	rec := srv.do(http.MethodPost, "/api/chirps", `{"body":"pick one","user_id":"`+user.ID+`","poll":{"options":["a","b"]}}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400 (%s)", rec.Code, rec.Body)
+	}
+	if got := decode[errorResponse](t, rec); got.Field != "poll" {
+		t.Errorf("field = %q, want poll", got.Field)
+	}
+}
+
+func TestPollVotesAreCountedOncePerClient(t *testing.T) {
+	srv, polls := newPollsTestServer(t)
+	author := srv.createUser("author@example.com")
+	chirp := srv.createChirp(author.ID, "tabs or spaces?")
+	polls.add(chirp.ID, []string{"tabs", "spaces"}, time.Now().UTC().Add(time.Hour))
+
+	rec := srv.vote(chirp.ID, "203.0.113.7:1234", `{"user_id":"`+uuid.NewString()+`","option":1}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201 (%s)", rec.Code, rec.Body)
+	}
+	if got := decode[pollResponse](t, rec); got.MyVote == nil || *got.MyVote != 1 || got.Tallies != nil {
+		t.Errorf("vote response = %+v, want my_vote 1 and no tallies", got)
+	}
+
+	// Made-up user IDs from the same address don't get another vote
+	for _, remoteAddr := range []string{"203.0.113.7:1234", "203.0.113.7:5678"} {
+		rec := srv.vote(chirp.ID, remoteAddr, `{"user_id":"`+uuid.NewString()+`","option":0}`)
+		if got := decode[errorResponse](t, rec); rec.Code != http.StatusConflict || got.Code != "already_voted" {
+			t.Errorf("vote again from %s: status = %d, code = %q, want 409 already_voted", remoteAddr, rec.Code, got.Code)
+		}
+	}
+	if rec := srv.vote(chirp.ID, "198.51.100.2:1234", `{"option":0}`); rec.Code != http.StatusCreated {
+		t.Errorf("vote from another address: status = %d, want 201 (%s)", rec.Code, rec.Body)
+	}
+
+	for _, body := range []string{`{}`, `{"option":2}`, `{"option":-1}`} {
+		rec := srv.vote(chirp.ID, "192.0.2.1:1234", body)
+		if got := decode[errorResponse](t, rec); rec.Code != http.StatusBadRequest || got.Field != "option" {
+			t.Errorf("%s: status = %d, field = %q, want 400 on option", body, rec.Code, got.Field)
+		}
+	}
+	if rec := srv.vote(uuid.NewString(), "192.0.2.1:1234", `{"option":0}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown chirp: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestPollTalliesHiddenUntilClosed(t *testing.T) {
+	srv, polls := newPollsTestServer(t)
+	author := srv.createUser("author@example.com")
+	chirp := srv.createChirp(author.ID, "tabs or spaces?")
+	polls.add(chirp.ID, []string{"tabs", "spaces"}, time.Now().UTC().Add(time.Hour))
+	srv.vote(chirp.ID, "203.0.113.7:1234", `{"option":1}`)
+
+	// Not even the author's user_id unlocks the tallies of an open poll
+	got := decode[chirpResponse](t, srv.do(http.MethodGet, "/api/chirps/"+chirp.ID+"?user_id="+author.ID, ""))
+	if got.Poll == nil || got.Poll.Tallies != nil || got.Poll.MyVote != nil {
+		t.Errorf("open poll = %+v, want no tallies and no my_vote", got.Poll)
+	}
+	batch := decode[chirpBatchResponse](t, srv.do(http.MethodPost, "/api/chirps/batch", `{"ids":["`+chirp.ID+`"],"user_id":"`+author.ID+`"}`))
+	if poll := batch.Chirps[0].Poll; poll == nil || poll.Tallies != nil {
+		t.Errorf("open poll in batch = %+v, want no tallies", poll)
+	}
+
+	polls.add(chirp.ID, []string{"tabs", "spaces"}, time.Now().UTC().Add(-time.Minute))
+	got = decode[chirpResponse](t, srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, ""))
+	if got.Poll == nil || !got.Poll.Closed || len(got.Poll.Tallies) != 2 || got.Poll.Tallies[1] != 1 {
+		t.Errorf("closed poll = %+v, want tallies [0 1]", got.Poll)
+	}
+	rec := srv.vote(chirp.ID, "198.51.100.2:1234", `{"option":0}`)
+	if got := decode[errorResponse](t, rec); rec.Code != http.StatusConflict || got.Code != "poll_closed" {
+		t.Errorf("vote after close: status = %d, code = %q, want 409 poll_closed", rec.Code, got.Code)
+	}
+}
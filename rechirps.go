@@ -0,0 +1,123 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// rechirpRequest represents the incoming JSON payload
+type rechirpRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// rechirpHandler handles rechirp creation (POST) and removal (DELETE)
+func (cfg *apiConfig) rechirpHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	chirpID, err := request.ParseUUIDParam(r, "chirpID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Read and parse request body
+	var req rechirpRequest
+	err = request.DecodeJSON(r, &req, request.DefaultMaxBodyBytes)
+	if err == nil {
+		err = request.RequireUUID("user_id", req.UserID)
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Make sure the chirp exists
+	chirp, err := cfg.getVisibleChirp(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirp"})
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		removed, err := cfg.store.DeleteRechirp(r.Context(), database.DeleteRechirpParams{
+			UserID:  req.UserID,
+			ChirpID: chirp.ID,
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to remove rechirp"})
+			return
+		}
+		if removed == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Rechirp not found"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Users can only rechirp someone else's chirp
+	if chirp.UserID == req.UserID {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Cannot rechirp your own chirp"})
+		return
+	}
+
+	// Rechirps stay within a tenant
+	_, err = cfg.store.GetUser(r.Context(), database.GetUserParams{ID: req.UserID, TenantID: chirp.TenantID})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "User not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get user"})
+		return
+	}
+
+	added, err := cfg.store.CreateRechirp(r.Context(), database.CreateRechirpParams{
+		UserID:    req.UserID,
+		ChirpID:   chirp.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to rechirp"})
+		return
+	}
+
+	resp, err := cfg.chirpToResponse(r.Context(), chirp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to count rechirps"})
+		return
+	}
+
+	// Return the rechirped chirp, with 200 if it was already rechirped
+	status := http.StatusCreated
+	if added == 0 {
+		status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// Report statuses
+const (
+	reportOpen      = "open"
+	reportResolved  = "resolved"  // upheld: the chirp broke the rules
+	reportDismissed = "dismissed" // the chirp was fine
+)
+
+// Report limits and admin page sizes
+const (
+	maxReportDetailsLength = 500
+	reportsDefaultLimit    = 50
+	reportsMaxLimit        = 200
+)
+
+// Audited report actions
+const (
+	auditActionResolveReport = "resolve_report"
+	auditActionDismissReport = "dismiss_report"
+)
+
+// errReportClosed is returned when another review closed a report first
+var errReportClosed = errors.New("report already reviewed")
+
+// reportRequest represents the incoming JSON payload
+type reportRequest struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Reason  string    `json:"reason"`
+	Details string    `json:"details"`
+}
+
+// reportResponse represents a report
+type reportResponse struct {
+	ID         string     `json:"id"`
+	ChirpID    string     `json:"chirp_id"`
+	ReporterID string     `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// adminReportResponse is a report in the review queue, with the reported
+// chirp
+type adminReportResponse struct {
+	reportResponse
+	ChirpBody     string `json:"chirp_body"`
+	ChirpAuthorID string `json:"chirp_author_id"`
+	ChirpHidden   bool   `json:"chirp_hidden"`
+}
+
+// reportsResponse represents a page of the review queue
+type reportsResponse struct {
+	Reports    []adminReportResponse `json:"reports"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// reportToResponse converts a stored report to its response
+func reportToResponse(report database.Report) reportResponse {
+	resp := reportResponse{
+		ID:         report.ID.String(),
+		ChirpID:    report.ChirpID.String(),
+		ReporterID: report.ReporterID.String(),
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Status:     report.Status,
+		CreatedAt:  report.CreatedAt,
+	}
+	if report.ReviewedAt.Valid {
+		resp.ReviewedAt = &report.ReviewedAt.Time
+	}
+	return resp
+}
+
+// reportChirpHandler files a report against a chirp. Each user reports a
+// chirp once, and a chirp reported from enough client addresses is hidden
+// until an admin reviews it. The reporter's user_id isn't verified, so
+// counting users would let anyone hide a chirp with other users' IDs.
+func (cfg *apiConfig) reportChirpHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	chirpID, err := request.ParseUUIDParam(r, "chirpID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Read and parse request body
+	var req reportRequest
+	err = request.DecodeJSON(r, &req, request.DefaultMaxBodyBytes)
+	if err == nil {
+		err = request.RequireUUID("user_id", req.UserID)
+	}
+	if err == nil && utf8.RuneCountInString(req.Details) > maxReportDetailsLength {
+		err = &request.FieldError{Field: "details", Message: fmt.Sprintf("must be at most %d characters", maxReportDetailsLength)}
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Admins configure the reasons under /admin/rules
+	reasons, err := cfg.reviews.ListReportReasons(r.Context(), tenantID(r.Context()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get report reasons"})
+		return
+	}
+	codes := make([]string, len(reasons))
+	for i, reason := range reasons {
+		codes[i] = reason.Code
+	}
+	if !slices.Contains(codes, req.Reason) {
+		respondWithRequestError(w, &request.FieldError{Field: "reason", Message: "must be one of " + strings.Join(codes, ", ")})
+		return
+	}
+
+	chirp, err := cfg.getVisibleChirp(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirp"})
+		return
+	}
+	if chirp.UserID == req.UserID {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Cannot report your own chirp"})
+		return
+	}
+
+	_, err = cfg.store.GetUser(r.Context(), database.GetUserParams{ID: req.UserID, TenantID: tenantID(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "User not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get user"})
+		return
+	}
+
+	report := database.Report{
+		ID:           uuid.New(),
+		ChirpID:      chirp.ID,
+		ReporterID:   req.UserID,
+		Reason:       req.Reason,
+		Details:      strings.TrimSpace(req.Details),
+		Status:       reportOpen,
+		CreatedAt:    time.Now().UTC(),
+		ReporterAddr: clientIP(r),
+	}
+	created, err := cfg.reviews.CreateReport(r.Context(), database.CreateReportParams{
+		ID:           report.ID,
+		ChirpID:      report.ChirpID,
+		ReporterID:   report.ReporterID,
+		Reason:       report.Reason,
+		Details:      report.Details,
+		CreatedAt:    report.CreatedAt,
+		ReporterAddr: report.ReporterAddr,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to report chirp"})
+		return
+	}
+	if created == 0 {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(errorResponse{Error: "You already reported this chirp", Code: "already_reported"})
+		return
+	}
+
+	// Hide the chirp once it's been reported from enough addresses
+	addrs, err := cfg.reviews.CountOpenReportAddresses(r.Context(), chirp.ID)
+	if err == nil && addrs >= int64(cfg.reports.HideThreshold) {
+		err = holdChirp(r.Context(), cfg.reviews, chirp.ID, holdReports, fmt.Sprintf("reported from %d addresses", addrs))
+	}
+	if err != nil {
+		log.Printf("failed to check report threshold for chirp %s: %v", chirp.ID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reportToResponse(report))
+}
+
+// reportsHandler lists reports with a status (open by default), oldest
+// first, paginated with cursor and limit
+func (cfg *apiConfig) reportsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	params := database.ListReportsParams{Status: reportOpen, TenantID: tenantID(r.Context())}
+	if status := query.Get("status"); status != "" {
+		params.Status = status
+	}
+
+	limit, err := request.ParseInt(r, "limit", reportsDefaultLimit, 1, reportsMaxLimit)
+	if err == nil && !slices.Contains([]string{reportOpen, reportResolved, reportDismissed}, params.Status) {
+		err = &request.FieldError{Field: "status", Message: "must be open, resolved or dismissed"}
+	}
+	if err == nil && query.Get("cursor") != "" {
+		params.AfterCreatedAt, params.AfterID, err = cfg.decodePageCursor(query.Get("cursor"))
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+	params.RowLimit = int32(limit)
+
+	reports, err := cfg.reviews.ListReports(r.Context(), params)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list reports"})
+		return
+	}
+
+	// Load the reported chirps and whether they're hidden
+	var ids []uuid.UUID
+	for _, report := range reports {
+		ids = append(ids, report.ChirpID)
+	}
+	chirps, err := cfg.store.GetChirpsByIDs(r.Context(), database.GetChirpsByIDsParams{
+		TenantID: tenantID(r.Context()),
+		Ids:      ids,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list reports"})
+		return
+	}
+	held, err := cfg.heldChirpIDs(r.Context(), ids)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list reports"})
+		return
+	}
+	byID := make(map[uuid.UUID]database.Chirp, len(chirps))
+	for _, chirp := range chirps {
+		byID[chirp.ID] = chirp
+	}
+
+	resp := reportsResponse{Reports: []adminReportResponse{}}
+	for _, report := range reports {
+		// Skip reports on chirps deleted since the reports were read
+		chirp, ok := byID[report.ChirpID]
+		if !ok {
+			continue
+		}
+		resp.Reports = append(resp.Reports, adminReportResponse{
+			reportResponse: reportToResponse(report),
+			ChirpBody:      chirp.Body,
+			ChirpAuthorID:  chirp.UserID.String(),
+			ChirpHidden:    held[report.ChirpID],
+		})
+	}
+	if len(reports) == limit {
+		last := reports[len(reports)-1]
+		resp.NextCursor, err = cfg.cursors.Encode(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to list reports"})
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// resolveReportHandler upholds a report. The decision covers the chirp, so
+// every open report on it is resolved, the chirp stays hidden for good and
+// its author gets a strike.
+func (cfg *apiConfig) resolveReportHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.reviewReport(w, r, reportResolved)
+}
+
+// dismissReportHandler dismisses a report. Every open report on the chirp
+// is dismissed and the chirp is shown again if reports had hidden it.
+func (cfg *apiConfig) dismissReportHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.reviewReport(w, r, reportDismissed)
+}
+
+// reviewReport closes a report and the other open reports on its chirp
+// with status
+func (cfg *apiConfig) reviewReport(w http.ResponseWriter, r *http.Request, status string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	reportID, err := request.ParseUUIDParam(r, "reportID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	report, err := cfg.reviews.GetReport(r.Context(), database.GetReportParams{ID: reportID, TenantID: tenantID(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Report not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get report"})
+		return
+	}
+	if report.Status != reportOpen {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Report was already reviewed", Code: "report_closed"})
+		return
+	}
+
+	// Closing the reports and hiding or showing the chirp happen together,
+	// so a failure leaves the reports open to retry
+	now := time.Now().UTC()
+	err = cfg.reviewTx(r.Context(), func(tx chirpReviews) error {
+		closed, err := tx.ReviewChirpReports(r.Context(), database.ReviewChirpReportsParams{
+			ChirpID:    report.ChirpID,
+			Status:     status,
+			ReviewedAt: sql.NullTime{Time: now, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if closed == 0 {
+			return errReportClosed
+		}
+		if status == reportResolved {
+			return holdChirp(r.Context(), tx, report.ChirpID, holdRemoved, "upheld report: "+report.Reason)
+		}
+		_, err = tx.ReleaseChirp(r.Context(), database.ReleaseChirpParams{
+			ChirpID: report.ChirpID,
+			Reason:  holdReports,
+		})
+		return err
+	})
+	if errors.Is(err, errReportClosed) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Report was already reviewed", Code: "report_closed"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to review report"})
+		return
+	}
+
+	if status == reportResolved {
+		cfg.recordAudit(r, auditActionResolveReport, report.ChirpID.String(), report.Reason)
+
+		// The author gets one strike per upheld chirp, however many reports it had
+		chirp, err := cfg.store.GetChirp(r.Context(), database.GetChirpParams{ID: report.ChirpID, TenantID: tenantID(r.Context())})
+		if err == nil {
+			err = cfg.addStrike(r.Context(), chirp.UserID, strikeUpheldReport, chirp.ID.String())
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("failed to add strike for chirp %s: %v", report.ChirpID, err)
+		}
+	} else {
+		cfg.recordAudit(r, auditActionDismissReport, report.ChirpID.String(), report.Reason)
+	}
+
+	report.Status = status
+	report.ReviewedAt = sql.NullTime{Time: now, Valid: true}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reportToResponse(report))
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// memoryReviews keeps reports and holds in memory the way the Postgres
+// queries do
+type memoryReviews struct {
+	mu      sync.Mutex
+	store   store.Store
+	holds   map[uuid.UUID]database.ChirpHold
+	reports map[uuid.UUID]database.Report
+
+	// afterGetHold, if set, runs after every hold lookup
+	afterGetHold func()
+}
+
+func newMemoryReviews(st store.Store) *memoryReviews {
+	return &memoryReviews{
+		store:   st,
+		holds:   map[uuid.UUID]database.ChirpHold{},
+		reports: map[uuid.UUID]database.Report{},
+	}
+}
+
+// inTenant reports whether a chirp belongs to tenantID, like the queries'
+// join on chirps
+func (m *memoryReviews) inTenant(ctx context.Context, chirpID, tenantID uuid.UUID) bool {
+	_, err := m.store.GetChirp(ctx, database.GetChirpParams{ID: chirpID, TenantID: tenantID})
+	return err == nil
+}
+
+func (m *memoryReviews) HoldChirp(ctx context.Context, arg database.HoldChirpParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.holds[arg.ChirpID] = database.ChirpHold{ChirpID: arg.ChirpID, Reason: arg.Reason, CreatedAt: arg.CreatedAt, Details: arg.Details}
+	return nil
+}
+
+func (m *memoryReviews) ReleaseChirp(ctx context.Context, arg database.ReleaseChirpParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hold, ok := m.holds[arg.ChirpID]; !ok || hold.Reason != arg.Reason {
+		return 0, nil
+	}
+	delete(m.holds, arg.ChirpID)
+	return 1, nil
+}
+
+func (m *memoryReviews) UpdateChirpHoldReason(ctx context.Context, arg database.UpdateChirpHoldReasonParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hold, ok := m.holds[arg.ChirpID]
+	if !ok || hold.Reason != arg.Reason {
+		return 0, nil
+	}
+	hold.Reason = arg.NewReason
+	m.holds[arg.ChirpID] = hold
+	return 1, nil
+}
+
+func (m *memoryReviews) IsChirpHeld(ctx context.Context, chirpID uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.holds[chirpID]
+	return ok, nil
+}
+
+func (m *memoryReviews) GetChirpHold(ctx context.Context, arg database.GetChirpHoldParams) (database.ChirpHold, error) {
+	m.mu.Lock()
+	hold, ok := m.holds[arg.ChirpID]
+	after := m.afterGetHold
+	m.mu.Unlock()
+	if after != nil {
+		after()
+	}
+	if !ok || !m.inTenant(ctx, arg.ChirpID, arg.TenantID) {
+		return database.ChirpHold{}, sql.ErrNoRows
+	}
+	return hold, nil
+}
+
+func (m *memoryReviews) GetHeldChirpIDs(ctx context.Context, chirpIDs []uuid.UUID) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var held []uuid.UUID
+	for _, id := range chirpIDs {
+		if _, ok := m.holds[id]; ok {
+			held = append(held, id)
+		}
+	}
+	return held, nil
+}
+
+func (m *memoryReviews) ListChirpHolds(ctx context.Context, arg database.ListChirpHoldsParams) ([]database.ChirpHold, error) {
+	m.mu.Lock()
+	holds := slices.Collect(maps.Values(m.holds))
+	m.mu.Unlock()
+	slices.SortFunc(holds, func(a, b database.ChirpHold) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ChirpID.String(), b.ChirpID.String()))
+	})
+	var page []database.ChirpHold
+	for _, hold := range holds {
+		after := hold.CreatedAt.After(arg.AfterCreatedAt) ||
+			(hold.CreatedAt.Equal(arg.AfterCreatedAt) && hold.ChirpID.String() > arg.AfterID.String())
+		if hold.Reason == arg.Reason && after && m.inTenant(ctx, hold.ChirpID, arg.TenantID) && len(page) < int(arg.RowLimit) {
+			page = append(page, hold)
+		}
+	}
+	return page, nil
+}
+
+func (m *memoryReviews) ListReportReasons(ctx context.Context, tenantID uuid.UUID) ([]database.ReportReason, error) {
+	return []database.ReportReason{{Code: "spam", Label: "Spam"}, {Code: "other", Label: "Something else", Position: 1}}, nil
+}
+
+func (m *memoryReviews) CreateReport(ctx context.Context, arg database.CreateReportParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, report := range m.reports {
+		if report.ChirpID == arg.ChirpID && report.ReporterID == arg.ReporterID {
+			return 0, nil
+		}
+	}
+	m.reports[arg.ID] = database.Report{
+		ID:           arg.ID,
+		ChirpID:      arg.ChirpID,
+		ReporterID:   arg.ReporterID,
+		Reason:       arg.Reason,
+		Details:      arg.Details,
+		Status:       reportOpen,
+		CreatedAt:    arg.CreatedAt,
+		ReporterAddr: arg.ReporterAddr,
+	}
+	return 1, nil
+}
+
+func (m *memoryReviews) CountOpenReportAddresses(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	addrs := map[string]bool{}
+	for _, report := range m.reports {
+		if report.ChirpID == chirpID && report.Status == reportOpen {
+			addrs[report.ReporterAddr] = true
+		}
+	}
+	return int64(len(addrs)), nil
+}
+
+func (m *memoryReviews) GetReport(ctx context.Context, arg database.GetReportParams) (database.Report, error) {
+	m.mu.Lock()
+	report, ok := m.reports[arg.ID]
+	m.mu.Unlock()
+	if !ok || !m.inTenant(ctx, report.ChirpID, arg.TenantID) {
+		return database.Report{}, sql.ErrNoRows
+	}
+	return report, nil
+}
+
+func (m *memoryReviews) ListReports(ctx context.Context, arg database.ListReportsParams) ([]database.Report, error) {
+	m.mu.Lock()
+	reports := slices.Collect(maps.Values(m.reports))
+	m.mu.Unlock()
+	slices.SortFunc(reports, func(a, b database.Report) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID.String(), b.ID.String()))
+	})
+	var page []database.Report
+	for _, report := range reports {
+		after := report.CreatedAt.After(arg.AfterCreatedAt) ||
+			(report.CreatedAt.Equal(arg.AfterCreatedAt) && report.ID.String() > arg.AfterID.String())
+		if report.Status == arg.Status && after && m.inTenant(ctx, report.ChirpID, arg.TenantID) && len(page) < int(arg.RowLimit) {
+			page = append(page, report)
+		}
+	}
+	return page, nil
+}
+
+func (m *memoryReviews) ReviewChirpReports(ctx context.Context, arg database.ReviewChirpReportsParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for id, report := range m.reports {
+		if report.ChirpID == arg.ChirpID && report.Status == reportOpen {
+			report.Status = arg.Status
+			report.ReviewedAt = arg.ReviewedAt
+			m.reports[id] = report
+			n++
+		}
+	}
+	return n, nil
+}
+
+// newReviewsTestServer serves the report and moderation routes with
+// reports and holds kept in memory
+func newReviewsTestServer(t *testing.T, c config.Config) (*testServer, *memoryReviews) {
+	st := store.NewMemory()
+	cfg := newAPIConfig(c, st)
+	cfg.reviews = newMemoryReviews(st)
+	handler := cfg.mount([]route{
+		{pattern: "/api/users", handler: cfg.createUserHandler},
+		{pattern: "/api/chirps", handler: cfg.createChirpHandler},
+		{pattern: "/api/chirps/{chirpID}", handler: cfg.getChirpHandler},
+		{pattern: "/api/chirps/{chirpID}/report", handler: cfg.reportChirpHandler},
+		{pattern: "/admin/reports", handler: cfg.reportsHandler, admin: true},
+		{pattern: "/admin/reports/{reportID}/resolve", handler: cfg.resolveReportHandler, admin: true},
+		{pattern: "/admin/reports/{reportID}/dismiss", handler: cfg.dismissReportHandler, admin: true},
+		{pattern: "/admin/moderation", handler: cfg.moderationQueueHandler, admin: true},
+		{pattern: "/admin/moderation/{chirpID}/approve", handler: cfg.approveChirpHandler, admin: true},
+		{pattern: "/admin/moderation/{chirpID}/reject", handler: cfg.rejectChirpHandler, admin: true},
+	})
+	return &testServer{t: t, handler: handler, store: st}, cfg.reviews.(*memoryReviews)
+}
+
+// report files a report on a chirp from a client address
+func (s *testServer) report(chirpID, userID, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirpID+"/report", strings.NewReader(`{"user_id":"`+userID+`","reason":"spam"}`))
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReportChirp(t *testing.T) {
+	c := testConfig()
+	c.Reports.HideThreshold = 2
+	srv, _ := newReviewsTestServer(t, c)
+	author := srv.createUser("author@example.com")
+	chirp := srv.createChirp(author.ID, "report me")
+	var reporters []string
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		reporters = append(reporters, srv.createUser(email).ID)
+	}
+
+	if rec := srv.report(chirp.ID, author.ID, "203.0.113.7:1"); rec.Code != http.StatusBadRequest {
+		t.Errorf("self-report: status = %d, want 400 (%s)", rec.Code, rec.Body)
+	}
+	if rec := srv.report(chirp.ID, reporters[0], "203.0.113.7:1"); rec.Code != http.StatusCreated {
+		t.Fatalf("report: status = %d, want 201 (%s)", rec.Code, rec.Body)
+	}
+	rec := srv.report(chirp.ID, reporters[0], "198.51.100.2:1")
+	if rec.Code != http.StatusConflict || decode[errorResponse](t, rec).Code != "already_reported" {
+		t.Errorf("duplicate: status = %d, want 409 already_reported (%s)", rec.Code, rec.Body)
+	}
+	if rec := srv.report(chirp.ID, uuid.NewString(), "198.51.100.2:1"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown reporter: status = %d, want 404", rec.Code)
+	}
+
+	// Reports from one address count once toward the threshold
+	if rec := srv.report(chirp.ID, reporters[1], "203.0.113.7:2"); rec.Code != http.StatusCreated {
+		t.Fatalf("second report: status = %d, want 201 (%s)", rec.Code, rec.Body)
+	}
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("reported from one address: status = %d, want 200", rec.Code)
+	}
+	if rec := srv.report(chirp.ID, reporters[2], "198.51.100.2:1"); rec.Code != http.StatusCreated {
+		t.Fatalf("third report: status = %d, want 201 (%s)", rec.Code, rec.Body)
+	}
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("reported from two addresses: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestReviewReport(t *testing.T) {
+	c := testConfig()
+	c.Reports.HideThreshold = 1
+	srv, _ := newReviewsTestServer(t, c)
+	author := srv.createUser("author@example.com")
+	reporter := srv.createUser("reporter@example.com")
+	fine := srv.createChirp(author.ID, "this is fine")
+	rude := srv.createChirp(author.ID, "this is rude")
+
+	fileReport := func(chirpID string) reportResponse {
+		t.Helper()
+		rec := srv.report(chirpID, reporter.ID, "203.0.113.7:1")
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("report: status = %d, want 201 (%s)", rec.Code, rec.Body)
+		}
+		return decode[reportResponse](t, rec)
+	}
+	dismissed := fileReport(fine.ID)
+	resolved := fileReport(rude.ID)
+
+	rec := srv.do(http.MethodGet, "/admin/reports", "")
+	if got := decode[reportsResponse](t, rec); len(got.Reports) != 2 || !got.Reports[0].ChirpHidden {
+		t.Errorf("queue = %+v, want both reports with hidden chirps", got.Reports)
+	}
+
+	// Dismissing shows the chirp again; resolving keeps it hidden
+	if rec := srv.do(http.MethodPost, "/admin/reports/"+dismissed.ID+"/dismiss", ""); rec.Code != http.StatusOK {
+		t.Fatalf("dismiss: status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+fine.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("dismissed chirp: status = %d, want 200", rec.Code)
+	}
+	rec = srv.do(http.MethodPost, "/admin/reports/"+resolved.ID+"/resolve", "")
+	if rec.Code != http.StatusOK || decode[reportResponse](t, rec).Status != reportResolved {
+		t.Fatalf("resolve: status = %d, want 200 and resolved (%s)", rec.Code, rec.Body)
+	}
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+rude.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("resolved chirp: status = %d, want 404", rec.Code)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		want     int
+		wantCode string
+	}{
+		{"already resolved", "/admin/reports/" + resolved.ID + "/dismiss", http.StatusConflict, "report_closed"},
+		{"already dismissed", "/admin/reports/" + dismissed.ID + "/resolve", http.StatusConflict, "report_closed"},
+		{"unknown", "/admin/reports/" + uuid.NewString() + "/resolve", http.StatusNotFound, ""},
+		{"bad id", "/admin/reports/nope/resolve", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		rec := srv.do(http.MethodPost, tt.path, "")
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, rec.Code, tt.want, rec.Body)
+		}
+		if tt.wantCode != "" && decode[errorResponse](t, rec).Code != tt.wantCode {
+			t.Errorf("%s: code != %s", tt.name, tt.wantCode)
+		}
+	}
+}
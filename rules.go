@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// Instance rule and report reason limits
+const (
+	maxInstanceRules         = 50
+	maxRuleTitleLength       = 100
+	maxRuleDescriptionLength = 1000
+	maxReportReasons         = 20
+	maxReportReasonLabel     = 50
+)
+
+// Audited rule actions
+const (
+	auditActionUpdateRules = "update_rules"
+)
+
+// reportReasonCode is what a report reason code looks like, e.g. self_harm
+var reportReasonCode = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// ruleResponse is an instance rule. Number counts from 1 in display order.
+type ruleResponse struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// reportReasonResponse is a reason a chirp can be reported for
+type reportReasonResponse struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// instanceRulesResponse represents the instance rules and report reasons
+type instanceRulesResponse struct {
+	Rules         []ruleResponse         `json:"rules"`
+	ReportReasons []reportReasonResponse `json:"report_reasons"`
+}
+
+// rulesRequest represents the incoming JSON payload. Both lists replace
+// what is stored, in order.
+type rulesRequest struct {
+	Rules []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	} `json:"rules"`
+	ReportReasons []reportReasonResponse `json:"report_reasons"`
+}
+
+// validateRules trims and checks a rules update in place
+func validateRules(req *rulesRequest) error {
+	if len(req.Rules) > maxInstanceRules {
+		return &request.FieldError{Field: "rules", Message: fmt.Sprintf("must have at most %d rules", maxInstanceRules)}
+	}
+	for i := range req.Rules {
+		rule := &req.Rules[i]
+		rule.Title = strings.TrimSpace(rule.Title)
+		rule.Description = strings.TrimSpace(rule.Description)
+		if rule.Title == "" || utf8.RuneCountInString(rule.Title) > maxRuleTitleLength {
+			return &request.FieldError{
+				Field:   fmt.Sprintf("rules[%d].title", i),
+				Message: fmt.Sprintf("must be 1 to %d characters", maxRuleTitleLength),
+			}
+		}
+		if utf8.RuneCountInString(rule.Description) > maxRuleDescriptionLength {
+			return &request.FieldError{
+				Field:   fmt.Sprintf("rules[%d].description", i),
+				Message: fmt.Sprintf("must be at most %d characters", maxRuleDescriptionLength),
+			}
+		}
+	}
+
+	// Without a reason nothing could be reported
+	if len(req.ReportReasons) == 0 || len(req.ReportReasons) > maxReportReasons {
+		return &request.FieldError{
+			Field:   "report_reasons",
+			Message: fmt.Sprintf("must have between 1 and %d reasons", maxReportReasons),
+		}
+	}
+	seen := map[string]bool{}
+	for i := range req.ReportReasons {
+		reason := &req.ReportReasons[i]
+		reason.Label = strings.TrimSpace(reason.Label)
+		if !reportReasonCode.MatchString(reason.Code) {
+			return &request.FieldError{
+				Field:   fmt.Sprintf("report_reasons[%d].code", i),
+				Message: "must be up to 32 lowercase letters, digits and underscores",
+			}
+		}
+		if seen[reason.Code] {
+			return &request.FieldError{Field: fmt.Sprintf("report_reasons[%d].code", i), Message: "must be distinct"}
+		}
+		seen[reason.Code] = true
+		if reason.Label == "" || utf8.RuneCountInString(reason.Label) > maxReportReasonLabel {
+			return &request.FieldError{
+				Field:   fmt.Sprintf("report_reasons[%d].label", i),
+				Message: fmt.Sprintf("must be 1 to %d characters", maxReportReasonLabel),
+			}
+		}
+	}
+	return nil
+}
+
+// instanceRules loads a tenant's instance rules and report reasons
+func (cfg *apiConfig) instanceRules(ctx context.Context, tenantID uuid.UUID) (instanceRulesResponse, error) {
+	rules, err := cfg.db.ListInstanceRules(ctx, tenantID)
+	if err != nil {
+		return instanceRulesResponse{}, err
+	}
+	reasons, err := cfg.db.ListReportReasons(ctx, tenantID)
+	if err != nil {
+		return instanceRulesResponse{}, err
+	}
+
+	resp := instanceRulesResponse{
+		Rules:         []ruleResponse{},
+		ReportReasons: []reportReasonResponse{},
+	}
+	for i, rule := range rules {
+		resp.Rules = append(resp.Rules, ruleResponse{
+			Number:      i + 1,
+			Title:       rule.Title,
+			Description: rule.Description,
+		})
+	}
+	for _, reason := range reasons {
+		resp.ReportReasons = append(resp.ReportReasons, reportReasonResponse{
+			Code:  reason.Code,
+			Label: reason.Label,
+		})
+	}
+	return resp, nil
+}
+
+// instanceRulesHandler returns the instance rules and report reasons
+func (cfg *apiConfig) instanceRulesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cfg.respondWithInstanceRules(w, r)
+}
+
+// adminRulesHandler returns (GET) or replaces (PUT) the instance rules and
+// report reasons. Existing reports keep their reason even if it is removed.
+func (cfg *apiConfig) adminRulesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		cfg.respondWithInstanceRules(w, r)
+		return
+	}
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Read and parse request body
+	var req rulesRequest
+	err := request.DecodeJSON(r, &req, request.DefaultMaxBodyBytes)
+	if err == nil {
+		err = validateRules(&req)
+	}
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	if err := cfg.replaceInstanceRules(r.Context(), tenantID(r.Context()), req); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to update rules"})
+		return
+	}
+	cfg.recordAudit(r, auditActionUpdateRules, "rules",
+		fmt.Sprintf("%d rules, %d report reasons", len(req.Rules), len(req.ReportReasons)))
+
+	cfg.respondWithInstanceRules(w, r)
+}
+
+// replaceInstanceRules stores a validated rules update for a tenant in one
+// transaction
+func (cfg *apiConfig) replaceInstanceRules(ctx context.Context, tenantID uuid.UUID, req rulesRequest) error {
+	return cfg.store.WithTx(ctx, func(tx store.Store) error {
+		q := store.PostgresQueries(tx)
+		if err := q.DeleteInstanceRules(ctx, tenantID); err != nil {
+			return err
+		}
+		for i, rule := range req.Rules {
+			err := q.CreateInstanceRule(ctx, database.CreateInstanceRuleParams{
+				TenantID:    tenantID,
+				Position:    int32(i),
+				Title:       rule.Title,
+				Description: rule.Description,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := q.DeleteReportReasons(ctx, tenantID); err != nil {
+			return err
+		}
+		for i, reason := range req.ReportReasons {
+			err := q.CreateReportReason(ctx, database.CreateReportReasonParams{
+				TenantID: tenantID,
+				Code:     reason.Code,
+				Label:    reason.Label,
+				Position: int32(i),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// respondWithInstanceRules writes the instance rules and report reasons
+func (cfg *apiConfig) respondWithInstanceRules(w http.ResponseWriter, r *http.Request) {
+	resp, err := cfg.instanceRules(r.Context(), tenantID(r.Context()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get rules"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hydeh3r3/chirpy/internal/request"
+)
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{"valid", `{"rules":[{"title":" Be kind "}],"report_reasons":[{"code":"spam","label":"Spam"}]}`, ""},
+		{"no rules", `{"report_reasons":[{"code":"spam","label":"Spam"}]}`, ""},
+		{"empty title", `{"rules":[{"title":" "}],"report_reasons":[{"code":"spam","label":"Spam"}]}`, "rules[0].title"},
+		{"no reasons", `{"rules":[]}`, "report_reasons"},
+		{"bad code", `{"report_reasons":[{"code":"Spam!","label":"Spam"}]}`, "report_reasons[0].code"},
+		{"duplicate code", `{"report_reasons":[{"code":"spam","label":"Spam"},{"code":"spam","label":"Junk"}]}`, "report_reasons[1].code"},
+		{"long label", `{"report_reasons":[{"code":"spam","label":"` + strings.Repeat("x", maxReportReasonLabel+1) + `"}]}`, "report_reasons[0].label"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req rulesRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatal(err)
+			}
+			err := validateRules(&req)
+			if tt.wantField != "" {
+				var fieldErr *request.FieldError
+				if !errors.As(err, &fieldErr) || fieldErr.Field != tt.wantField {
+					t.Fatalf("err = %v, want a field error for %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if len(req.Rules) > 0 && req.Rules[0].Title != "Be kind" {
+				t.Errorf("title = %q, want it trimmed", req.Rules[0].Title)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/rand"
+	"log"
+	"net/http"
+
+	"github.com/hydeh3r3/chirpy/internal/cache"
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/cursor"
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/linkpreview"
+	"github.com/hydeh3r3/chirpy/internal/metrics"
+	"github.com/hydeh3r3/chirpy/internal/store"
+)
+
+// newAPIConfig builds the state shared by the handlers. The Postgres-only
+// parts (db and jobs) are left nil for main to fill in.
+func newAPIConfig(cfg config.Config, st store.Store) *apiConfig {
+	// Sign pagination cursors with a per-process key unless one is configured
+	cursorKey := []byte(cfg.CursorSecret)
+	if len(cursorKey) == 0 {
+		cursorKey = make([]byte, 32)
+		rand.Read(cursorKey)
+		log.Printf("CURSOR_SECRET is not set; pagination cursors will not survive a restart")
+	}
+	if cfg.Platform == config.PlatformProd && len(cfg.AdminTokens) == 0 {
+		log.Printf("ADMIN_TOKENS is not set; admin endpoints will return 403")
+	}
+
+	// Serve hot chirps and rechirp counts from Redis or memory
+	var cached *store.Cached
+	if cfg.Cache.Size > 0 {
+		cached = newCachedStore(st, cfg.Cache)
+		st = cached
+	}
+
+	return &apiConfig{
+		metrics:        metrics.New(),
+		store:          st,
+		cache:          cached,
+		platform:       cfg.Platform,
+		previews:       linkpreview.NewFetcher(),
+		emailDomain:    cfg.EmailGateway.Domain,
+		emailSecret:    cfg.EmailGateway.WebhookSecret,
+		cursors:        cursor.New(cursorKey),
+		requestTimeout: cfg.Timeouts.Request,
+		strikes:        cfg.Strikes,
+		reports:        cfg.Reports,
+		moderation:     cfg.Moderation,
+		web:            cfg.Web,
+		jsonCase:       cfg.JSONCase,
+		adminTokens:    cfg.AdminTokens,
+		tenants:        cache.New[string, database.Tenant]("tenants", tenantCacheSize, tenantCacheTTL),
+	}
+}
+
+// route is an endpoint and how it is served
+type route struct {
+	pattern string
+	handler http.HandlerFunc
+	// streaming routes write for as long as they need and are exempt from
+	// the request and write timeouts
+	streaming bool
+	// global routes serve the whole deployment and skip resolving a
+	// tenant, so probes keep working when the store is down
+	global bool
+	// admin routes are only served to admins (see middlewareAdmin)
+	admin bool
+}
+
+// routes registers every endpoint and wraps them in the shared middleware
+func (cfg *apiConfig) routes() http.Handler {
+	routes := []route{
+		// Health probes
+		{pattern: "/healthz", handler: healthzHandler, global: true},
+		{pattern: "/readyz", handler: cfg.readyzHandler, global: true},
+		{pattern: "/startupz", handler: cfg.startupzHandler, global: true},
+		{pattern: "/metrics", handler: cfg.prometheusHandler, global: true},
+
+		// API endpoints
+		{pattern: "/api/healthz", handler: healthzHandler},
+		{pattern: "/api/validate_chirp", handler: validateChirpHandler},
+		{pattern: "/api/chirps/batch", handler: cfg.chirpBatchHandler},
+		{pattern: "/api/chirps/{chirpID}", handler: cfg.getChirpHandler},
+		{pattern: "/api/chirps/{chirpID}/rechirp", handler: cfg.rechirpHandler},
+		{pattern: "/api/email/inbound", handler: cfg.inboundEmailHandler},
+
+		// Admin endpoints
+		{pattern: "/admin/metrics", handler: cfg.metricsHandler, admin: true},
+		{pattern: "/admin/stats", handler: cfg.statsHandler, admin: true},
+		{pattern: "/admin/reset", handler: cfg.resetHandler, admin: true},
+		{pattern: "/admin/emails/preview/{template}", handler: cfg.emailPreviewHandler, admin: true},
+		{pattern: "/admin/users/{userID}", handler: cfg.adminUserHandler, admin: true},
+	}
+	if cfg.db != nil {
+		// Endpoints that need Postgres beyond the store
+		routes = append(routes,
+			route{pattern: "/api/users", handler: cfg.middlewareIdempotency(cfg.createUserHandler)},
+			route{pattern: "/api/chirps", handler: cfg.middlewareIdempotency(cfg.createChirpHandler)},
+			route{pattern: "/api/chirps/{chirpID}/view", handler: cfg.recordViewHandler},
+			route{pattern: "/api/chirps/{chirpID}/poll/vote", handler: cfg.pollVoteHandler},
+			route{pattern: "/api/chirps/{chirpID}/report", handler: cfg.reportChirpHandler},
+			route{pattern: "/api/instance/rules", handler: cfg.instanceRulesHandler},
+			// Public, like the per-chirp view counts it adds up
+			route{pattern: "/api/users/{userID}/analytics/views", handler: cfg.authorViewsHandler},
+			route{pattern: "/admin/analytics", handler: cfg.analyticsHandler, admin: true},
+			route{pattern: "/admin/jobs", handler: cfg.jobsHandler, admin: true},
+			route{pattern: "/admin/audit", handler: cfg.auditLogHandler, admin: true},
+			route{pattern: "/admin/audit/export", handler: cfg.auditExportHandler, streaming: true, admin: true},
+			route{pattern: "/admin/reports", handler: cfg.reportsHandler, admin: true},
+			route{pattern: "/admin/reports/{reportID}/resolve", handler: cfg.resolveReportHandler, admin: true},
+			route{pattern: "/admin/reports/{reportID}/dismiss", handler: cfg.dismissReportHandler, admin: true},
+			route{pattern: "/admin/rules", handler: cfg.adminRulesHandler, admin: true},
+			route{pattern: "/admin/moderation", handler: cfg.moderationQueueHandler, admin: true},
+			route{pattern: "/admin/moderation/{chirpID}/approve", handler: cfg.approveChirpHandler, admin: true},
+			route{pattern: "/admin/moderation/{chirpID}/reject", handler: cfg.rejectChirpHandler, admin: true},
+		)
+	} else {
+		routes = append(routes,
+			route{pattern: "/api/users", handler: cfg.createUserHandler},
+			route{pattern: "/api/chirps", handler: cfg.createChirpHandler},
+		)
+	}
+	return cfg.mount(routes)
+}
+
+// mount serves routes and the web client under /app behind the shared
+// middleware
+func (cfg *apiConfig) mount(routes []route) http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		var handler http.Handler = rt.handler
+		if !rt.global {
+			handler = cfg.middlewareTenant(handler)
+		}
+		if rt.admin {
+			handler = cfg.middlewareAdmin(handler)
+		}
+		handler = middlewareTimeout(cfg.requestTimeout, rt.streaming, handler)
+		mux.Handle(rt.pattern, middlewareTracing(rt.pattern, cfg.middlewareMetrics(rt.pattern, handler)))
+	}
+
+	// Add the web client under /app
+	handler := http.StripPrefix("/app", staticHandler(webFiles(cfg.web.Root), cfg.web.CacheMaxAge))
+	mux.Handle("/app/", middlewareTracing("/app/", cfg.middlewareMetrics("/app/", handler)))
+
+	return middlewareRequestID(middlewareCompress(middlewareJSONCase(cfg.jsonCase, mux)))
+}
+
+// NewServer returns the API handler backed by st. Features that need
+// Postgres beyond the store (jobs, idempotency keys, the audit log and
+// analytics) are off, which makes it suitable for tests with store.NewMemory.
+func NewServer(cfg config.Config, st store.Store) http.Handler {
+	apiCfg := newAPIConfig(cfg, st)
+	apiCfg.started.Store(true)
+	return apiCfg.routes()
+}
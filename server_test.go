@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/store"
+)
+
+// testConfig is a dev config with the email gateway enabled
+func testConfig() config.Config {
+	return config.Config{
+		Platform:     config.PlatformDev,
+		CursorSecret: strings.Repeat("k", 32),
+		EmailGateway: config.EmailGateway{
+			Domain:        "post.example.com",
+			WebhookSecret: "webhook-secret",
+		},
+	}
+}
+
+// testServer is a server backed by an in-memory store
+type testServer struct {
+	t       *testing.T
+	handler http.Handler
+	store   *store.Memory
+}
+
+func newTestServer(t *testing.T, cfg config.Config) *testServer {
+	t.Helper()
+	st := store.NewMemory()
+	return &testServer{t: t, handler: NewServer(cfg, st), store: st}
+}
+
+// do sends a request with an optional JSON body and headers as key, value pairs
+func (s *testServer) do(method, path, body string, header ...string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode unmarshals a JSON response into v
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+// createUser creates a user and returns it
+func (s *testServer) createUser(email string) userResponse {
+	s.t.Helper()
+	rec := s.do(http.MethodPost, "/api/users", `{"email":"`+email+`"}`)
+	if rec.Code != http.StatusCreated {
+		s.t.Fatalf("creating user: %d %s", rec.Code, rec.Body)
+	}
+	return decode[userResponse](s.t, rec)
+}
+
+// createChirp posts a chirp and returns it
+func (s *testServer) createChirp(userID, body string) chirpResponse {
+	s.t.Helper()
+	rec := s.do(http.MethodPost, "/api/chirps", `{"body":"`+body+`","user_id":"`+userID+`"}`)
+	if rec.Code != http.StatusCreated {
+		s.t.Fatalf("creating chirp: %d %s", rec.Code, rec.Body)
+	}
+	return decode[chirpResponse](s.t, rec)
+}
+
+func TestValidateChirp(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantField  string
+	}{
+		{"clean", http.MethodPost, `{"body":"hello world"}`, http.StatusOK, "hello world", ""},
+		{"profanity", http.MethodPost, `{"body":"what a Kerfuffle"}`, http.StatusOK, "what a ****", ""},
+		{"long url counts as 23", http.MethodPost, `{"body":"https://example.com/` + strings.Repeat("a", 200) + `"}`, http.StatusOK, "", ""},
+		{"too long", http.MethodPost, `{"body":"` + strings.Repeat("a", 141) + `"}`, http.StatusBadRequest, "", ""},
+		{"invalid json", http.MethodPost, `{"body":`, http.StatusBadRequest, "", "body"},
+		{"wrong type", http.MethodPost, `{"body":1}`, http.StatusBadRequest, "", "body"},
+		{"wrong method", http.MethodGet, "", http.StatusMethodNotAllowed, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := srv.do(tt.method, "/api/validate_chirp", tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantBody != "" {
+				if got := decode[validateChirpResponse](t, rec); got.Body != tt.wantBody {
+					t.Errorf("body = %q, want %q", got.Body, tt.wantBody)
+				}
+			}
+			if tt.wantField != "" {
+				if got := decode[errorResponse](t, rec); got.Field != tt.wantField {
+					t.Errorf("field = %q, want %q", got.Field, tt.wantField)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantEmail  string
+	}{
+		{"created", `{"email":"a@example.com"}`, http.StatusCreated, "a@example.com"},
+		{"trimmed", `{"email":" b@example.com "}`, http.StatusCreated, "b@example.com"},
+		{"missing", `{}`, http.StatusBadRequest, ""},
+		{"not an address", `{"email":"nope"}`, http.StatusBadRequest, ""},
+		{"display name", `{"email":"Ann <ann@example.com>"}`, http.StatusBadRequest, ""},
+		{"too long", `{"email":"` + strings.Repeat("a", maxEmailLength) + `@example.com"}`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := srv.do(http.MethodPost, "/api/users", tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus == http.StatusCreated {
+				if got := decode[userResponse](t, rec); got.Email != tt.wantEmail {
+					t.Errorf("email = %q, want %q", got.Email, tt.wantEmail)
+				}
+				return
+			}
+			if got := decode[errorResponse](t, rec); got.Field != "email" {
+				t.Errorf("error = %+v, want field email", got)
+			}
+		})
+	}
+}
+
+func TestCreateChirp(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	user := srv.createUser("author@example.com")
+	srv.createChirp(user.ID, "first")
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantField  string
+	}{
+		{"created", `{"body":"second","user_id":"` + user.ID + `"}`, http.StatusCreated, "", ""},
+		{"duplicate", `{"body":"first","user_id":"` + user.ID + `"}`, http.StatusConflict, "duplicate_chirp", ""},
+		{"missing user", `{"body":"hi"}`, http.StatusBadRequest, "", "user_id"},
+		{"too long", `{"body":"` + strings.Repeat("a", 141) + `","user_id":"` + user.ID + `"}`, http.StatusBadRequest, "", ""},
+		{"unknown user", `{"body":"hi","user_id":"00000000-0000-0000-0000-000000000001"}`, http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := srv.do(http.MethodPost, "/api/chirps", tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus < 400 {
+				return
+			}
+			got := decode[errorResponse](t, rec)
+			if got.Code != tt.wantCode || got.Field != tt.wantField {
+				t.Errorf("error = %+v, want code %q field %q", got, tt.wantCode, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestGetChirp(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	user := srv.createUser("author@example.com")
+	chirp := srv.createChirp(user.ID, "hello")
+
+	rec := srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	if got := decode[chirpResponse](t, rec); got.Body != "hello" || got.UserID != user.ID {
+		t.Errorf("chirp = %+v", got)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	if vary := rec.Header().Values("Vary"); !slices.Contains(vary, tenantHeader) {
+		t.Errorf("Vary = %q, want it to include %s", vary, tenantHeader)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		header     []string
+		wantStatus int
+	}{
+		{"not modified", "/api/chirps/" + chirp.ID, []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"stale etag", "/api/chirps/" + chirp.ID, []string{"If-None-Match", `W/"stale"`}, http.StatusOK},
+		{"bad id", "/api/chirps/nope", nil, http.StatusBadRequest},
+		{"missing", "/api/chirps/00000000-0000-0000-0000-000000000001", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := srv.do(http.MethodGet, tt.path, "", tt.header...)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestRechirp(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	author := srv.createUser("author@example.com")
+	fan := srv.createUser("fan@example.com")
+	chirp := srv.createChirp(author.ID, "hello")
+	path := "/api/chirps/" + chirp.ID + "/rechirp"
+
+	steps := []struct {
+		name       string
+		method     string
+		userID     string
+		wantStatus int
+	}{
+		{"own chirp", http.MethodPost, author.ID, http.StatusBadRequest},
+		{"rechirp", http.MethodPost, fan.ID, http.StatusCreated},
+		{"rechirp again", http.MethodPost, fan.ID, http.StatusOK},
+		{"undo", http.MethodDelete, fan.ID, http.StatusNoContent},
+		{"undo again", http.MethodDelete, fan.ID, http.StatusNotFound},
+	}
+	for _, step := range steps {
+		rec := srv.do(step.method, path, `{"user_id":"`+step.userID+`"}`)
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d (%s)", step.name, rec.Code, step.wantStatus, rec.Body)
+		}
+		if step.name == "rechirp again" {
+			if got := decode[chirpResponse](t, rec); got.RechirpCount != 1 {
+				t.Errorf("rechirp count = %d, want 1", got.RechirpCount)
+			}
+		}
+	}
+}
+
+func TestInboundEmail(t *testing.T) {
+	cfg := testConfig()
+	srv := newTestServer(t, cfg)
+	author := srv.createUser("author@example.com")
+	address := author.PostingAddress
+	if address == "" {
+		t.Fatal("missing posting address")
+	}
+	auth := "Bearer " + cfg.EmailGateway.WebhookSecret
+
+	tests := []struct {
+		name       string
+		auth       string
+		body       string
+		wantStatus int
+	}{
+		{"posted", auth, `{"from":"author@example.com","to":"` + address + `","text":"from email"}`, http.StatusCreated},
+		{"no secret", "", `{"from":"author@example.com","to":"` + address + `","text":"hi"}`, http.StatusUnauthorized},
+		{"wrong secret", "Bearer nope", `{"from":"author@example.com","to":"` + address + `","text":"hi"}`, http.StatusUnauthorized},
+		{"wrong sender", auth, `{"from":"someone@example.com","to":"` + address + `","text":"hi"}`, http.StatusForbidden},
+		{"unknown address", auth, `{"from":"author@example.com","to":"nobody@post.example.com","text":"hi"}`, http.StatusNotFound},
+		{"empty", auth, `{"from":"author@example.com","to":"` + address + `","text":"> quoted"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := srv.do(http.MethodPost, "/api/email/inbound", tt.body, "Authorization", tt.auth)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	// The gateway is off without a domain
+	cfg.EmailGateway = config.EmailGateway{}
+	rec := newTestServer(t, cfg).do(http.MethodPost, "/api/email/inbound", `{}`, "Authorization", auth)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("disabled gateway: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestReset(t *testing.T) {
+	tests := []struct {
+		platform   string
+		wantStatus int
+		wantUsers  int64
+	}{
+		{config.PlatformProd, http.StatusForbidden, 1},
+		{config.PlatformDev, http.StatusOK, 0},
+		{config.PlatformDemo, http.StatusOK, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Platform = tt.platform
+			srv := newTestServer(t, cfg)
+			srv.createUser("a@example.com")
+
+			rec := srv.do(http.MethodPost, "/admin/reset", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			users, err := srv.store.CountUsers(context.Background(), store.DefaultTenantID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if users != tt.wantUsers {
+				t.Errorf("users = %d, want %d", users, tt.wantUsers)
+			}
+		})
+	}
+}
+
+func TestPostgresOnlyRoutesAreOff(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	for _, path := range []string{"/admin/analytics", "/admin/jobs", "/admin/audit", "/admin/audit/export", "/admin/reports", "/admin/moderation", "/admin/rules"} {
+		if rec := srv.do(http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status = %d, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestChirpBatch(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	author := srv.createUser("author@example.com")
+	fan := srv.createUser("fan@example.com")
+	first := srv.createChirp(author.ID, "first")
+	second := srv.createChirp(author.ID, "second")
+	srv.do(http.MethodPost, "/api/chirps/"+second.ID+"/rechirp", `{"user_id":"`+fan.ID+`"}`)
+	missing := "00000000-0000-0000-0000-000000000001"
+
+	rec := srv.do(http.MethodPost, "/api/chirps/batch", `{"ids":["`+second.ID+`","`+missing+`","`+first.ID+`"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	got := decode[chirpBatchResponse](t, rec).Chirps
+	if len(got) != 3 || got[0] == nil || got[1] != nil || got[2] == nil {
+		t.Fatalf("chirps = %+v, want [second nil first]", got)
+	}
+	if got[0].ID != second.ID || got[0].RechirpCount != 1 || got[2].ID != first.ID || got[2].RechirpCount != 0 {
+		t.Errorf("chirps = %+v, %+v", *got[0], *got[2])
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"empty", `{"ids":[]}`},
+		{"too many", `{"ids":[` + strings.TrimSuffix(strings.Repeat(`"`+missing+`",`, maxBatchChirps+1), ",") + `]}`},
+		{"bad id", `{"ids":["nope"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := srv.do(http.MethodPost, "/api/chirps/batch", tt.body); rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (%s)", rec.Code, rec.Body)
+			}
+		})
+	}
+}
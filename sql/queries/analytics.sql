@@ -0,0 +1,46 @@
+-- name: GetDailySignups :many
+SELECT date_trunc('day', created_at)::timestamp AS day, COUNT(*) AS signups
+FROM users
+WHERE tenant_id = $1 AND created_at >= $2
+GROUP BY day
+ORDER BY day;
+
+-- name: GetDailyActiveUsers :many
+SELECT date_trunc('day', created_at)::timestamp AS day, COUNT(DISTINCT user_id) AS active_users
+FROM user_activity
+WHERE tenant_id = $1 AND created_at >= $2
+GROUP BY day
+ORDER BY day;
+
+-- name: GetWeeklyActiveUsers :many
+SELECT date_trunc('week', created_at)::timestamp AS week, COUNT(DISTINCT user_id) AS active_users
+FROM user_activity
+WHERE tenant_id = $1 AND created_at >= $2
+GROUP BY week
+ORDER BY week;
+
+-- name: GetWeeklyCohortSizes :many
+SELECT date_trunc('week', created_at)::timestamp AS cohort_week, COUNT(*) AS users
+FROM users
+WHERE tenant_id = $1 AND created_at >= $2
+GROUP BY cohort_week
+ORDER BY cohort_week;
+
+-- name: GetWeeklyCohortRetention :many
+WITH cohorts AS (
+    SELECT id AS user_id, date_trunc('week', created_at)::timestamp AS cohort_week
+    FROM users
+    WHERE users.tenant_id = $1 AND users.created_at >= $2
+),
+activity AS (
+    SELECT DISTINCT user_id, date_trunc('week', created_at)::timestamp AS activity_week
+    FROM user_activity
+    WHERE user_activity.tenant_id = $1
+)
+SELECT cohorts.cohort_week,
+       ((activity.activity_week::date - cohorts.cohort_week::date) / 7)::int AS week_number,
+       COUNT(DISTINCT cohorts.user_id) AS active_users
+FROM cohorts
+JOIN activity ON activity.user_id = cohorts.user_id AND activity.activity_week >= cohorts.cohort_week
+GROUP BY cohorts.cohort_week, week_number
+ORDER BY cohorts.cohort_week, week_number;
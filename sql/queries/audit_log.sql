@@ -0,0 +1,14 @@
+-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (id, created_at, actor, action, target, request_id, details, tenant_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListAuditLog :many
+SELECT * FROM audit_log
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.arg(action)::text = '' OR action = sqlc.arg(action))
+  AND (sqlc.arg(actor)::text = '' OR actor = sqlc.arg(actor))
+  AND (sqlc.arg(target)::text = '' OR target = sqlc.arg(target))
+  AND created_at >= sqlc.arg(since)
+  AND (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
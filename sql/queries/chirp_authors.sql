@@ -0,0 +1,9 @@
+-- name: CreateChirpAuthor :exec
+INSERT INTO chirp_authors (chirp_id, user_id, email, captured_at)
+SELECT sqlc.arg(chirp_id), id, email, sqlc.arg(captured_at)
+FROM users
+WHERE id = sqlc.arg(user_id);
+
+-- name: GetChirpAuthor :one
+SELECT * FROM chirp_authors
+WHERE chirp_id = $1;
@@ -0,0 +1,33 @@
+-- name: HoldChirp :exec
+INSERT INTO chirp_holds (chirp_id, reason, details, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chirp_id) DO UPDATE SET reason = EXCLUDED.reason, details = EXCLUDED.details;
+
+-- name: ReleaseChirp :execrows
+DELETE FROM chirp_holds
+WHERE chirp_id = $1 AND reason = $2;
+
+-- name: IsChirpHeld :one
+SELECT EXISTS (SELECT 1 FROM chirp_holds WHERE chirp_id = $1);
+
+-- name: GetChirpHold :one
+SELECT h.* FROM chirp_holds h
+JOIN chirps c ON c.id = h.chirp_id
+WHERE h.chirp_id = $1 AND c.tenant_id = $2;
+
+-- name: GetHeldChirpIDs :many
+SELECT chirp_id FROM chirp_holds
+WHERE chirp_id = ANY($1::uuid[]);
+
+-- name: ListChirpHolds :many
+SELECT h.* FROM chirp_holds h
+JOIN chirps c ON c.id = h.chirp_id
+WHERE h.reason = sqlc.arg(reason) AND c.tenant_id = sqlc.arg(tenant_id)
+  AND (h.created_at, h.chirp_id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+ORDER BY h.created_at, h.chirp_id
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpdateChirpHoldReason :execrows
+UPDATE chirp_holds
+SET reason = sqlc.arg(new_reason)
+WHERE chirp_id = sqlc.arg(chirp_id) AND reason = sqlc.arg(reason);
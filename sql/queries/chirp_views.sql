@@ -0,0 +1,41 @@
+-- name: RecordChirpView :execrows
+INSERT INTO chirp_views (chirp_id, viewer, day)
+VALUES ($1, $2, $3)
+ON CONFLICT (chirp_id, viewer, day) DO NOTHING;
+
+-- name: PurgeChirpViews :execrows
+DELETE FROM chirp_views
+WHERE counted AND day < $1;
+
+-- name: RollupChirpViews :execrows
+WITH fresh AS (
+    UPDATE chirp_views SET counted = true
+    WHERE NOT counted
+    RETURNING chirp_id, day
+), daily AS (
+    INSERT INTO chirp_daily_views (chirp_id, day, views)
+    SELECT chirp_id, day, COUNT(*) FROM fresh
+    GROUP BY chirp_id, day
+    ON CONFLICT (chirp_id, day) DO UPDATE
+    SET views = chirp_daily_views.views + EXCLUDED.views
+)
+INSERT INTO chirp_view_counts (chirp_id, view_count)
+SELECT chirp_id, COUNT(*) FROM fresh
+GROUP BY chirp_id
+ON CONFLICT (chirp_id) DO UPDATE
+SET view_count = chirp_view_counts.view_count + EXCLUDED.view_count;
+
+-- name: GetChirpViewCount :one
+SELECT COALESCE((SELECT view_count FROM chirp_view_counts WHERE chirp_id = $1), 0)::bigint AS view_count;
+
+-- name: GetChirpViewCounts :many
+SELECT * FROM chirp_view_counts
+WHERE chirp_id = ANY($1::uuid[]);
+
+-- name: GetAuthorDailyViews :many
+SELECT v.day::timestamp AS day, SUM(v.views)::bigint AS views
+FROM chirp_daily_views v
+JOIN chirps c ON c.id = v.chirp_id
+WHERE c.user_id = $1 AND c.tenant_id = $2 AND v.day >= $3
+GROUP BY v.day
+ORDER BY v.day;
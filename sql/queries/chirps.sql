@@ -1,4 +1,18 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING *; 
\ No newline at end of file
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, tenant_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *; 
+
+-- name: GetChirp :one
+SELECT * FROM chirps
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetRecentDuplicateChirp :one
+SELECT * FROM chirps
+WHERE user_id = $1 AND body = $2 AND created_at >= $3
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = ANY(sqlc.arg(ids)::uuid[]);
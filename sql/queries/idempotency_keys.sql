@@ -0,0 +1,21 @@
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (tenant_id, key, endpoint, request_hash, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (tenant_id, key, endpoint) DO NOTHING;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE tenant_id = $1 AND key = $2 AND endpoint = $3;
+
+-- name: SaveIdempotentResponse :exec
+UPDATE idempotency_keys
+SET status_code = $4, content_type = $5, response_body = $6
+WHERE tenant_id = $1 AND key = $2 AND endpoint = $3;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE tenant_id = $1 AND key = $2 AND endpoint = $3;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at < $1;
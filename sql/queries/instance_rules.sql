@@ -0,0 +1,27 @@
+-- name: ListInstanceRules :many
+SELECT * FROM instance_rules
+WHERE tenant_id = $1
+ORDER BY position;
+
+-- name: DeleteInstanceRules :exec
+DELETE FROM instance_rules
+WHERE tenant_id = $1;
+
+-- name: CreateInstanceRule :exec
+INSERT INTO instance_rules (tenant_id, position, title, description)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListReportReasons :many
+SELECT * FROM report_reasons
+WHERE tenant_id = $1
+   OR (tenant_id = '00000000-0000-0000-0000-000000000000'
+       AND NOT EXISTS (SELECT 1 FROM report_reasons own WHERE own.tenant_id = $1))
+ORDER BY position;
+
+-- name: DeleteReportReasons :exec
+DELETE FROM report_reasons
+WHERE tenant_id = $1;
+
+-- name: CreateReportReason :exec
+INSERT INTO report_reasons (tenant_id, code, label, position)
+VALUES ($1, $2, $3, $4);
@@ -0,0 +1,53 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (id, kind, payload, status, attempts, max_attempts, run_at, created_at, updated_at, tenant_id)
+VALUES ($1, $2, $3, 'pending', 0, $4, $5, $6, $6, $7)
+RETURNING *;
+
+-- name: ClaimJob :one
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1, updated_at = $1
+WHERE id = (
+    SELECT id FROM jobs
+    WHERE status = 'pending' AND run_at <= $1
+    ORDER BY run_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'done', last_error = '', updated_at = $2
+WHERE id = $1;
+
+-- name: RetryJob :exec
+UPDATE jobs
+SET status = 'pending', run_at = $2, last_error = $3, updated_at = $4
+WHERE id = $1;
+
+-- name: FailJob :exec
+UPDATE jobs
+SET status = 'failed', last_error = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: RequeueStaleJobs :execrows
+UPDATE jobs
+SET status = 'pending', updated_at = $1
+WHERE status = 'running' AND updated_at < $2;
+
+-- name: PurgeDoneJobs :execrows
+DELETE FROM jobs
+WHERE status = 'done' AND updated_at < $1;
+
+-- name: GetJobCounts :many
+SELECT status, COUNT(*) AS count
+FROM jobs
+WHERE tenant_id = $1
+GROUP BY status
+ORDER BY status;
+
+-- name: ListFailedJobs :many
+SELECT * FROM jobs
+WHERE tenant_id = $1 AND status = 'failed'
+ORDER BY updated_at DESC
+LIMIT $2;
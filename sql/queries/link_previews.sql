@@ -0,0 +1,12 @@
+-- name: UpsertLinkPreview :exec
+INSERT INTO link_previews (url, title, description, image_url, fetched_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (url) DO UPDATE
+SET title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    image_url = EXCLUDED.image_url,
+    fetched_at = EXCLUDED.fetched_at;
+
+-- name: GetLinkPreviews :many
+SELECT * FROM link_previews
+WHERE url = ANY($1::text[]);
@@ -0,0 +1,22 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, options, closes_at, created_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetPoll :one
+SELECT * FROM polls
+WHERE chirp_id = $1;
+
+-- name: GetPollsByChirpIDs :many
+SELECT * FROM polls
+WHERE chirp_id = ANY($1::uuid[]);
+
+-- name: CastPollVote :execrows
+INSERT INTO poll_votes (chirp_id, voter, option_index, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chirp_id, voter) DO NOTHING;
+
+-- name: GetPollTallies :many
+SELECT chirp_id, option_index, COUNT(*) AS votes
+FROM poll_votes
+WHERE chirp_id = ANY($1::uuid[])
+GROUP BY chirp_id, option_index;
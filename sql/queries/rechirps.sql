@@ -0,0 +1,17 @@
+-- name: CreateRechirp :execrows
+INSERT INTO rechirps (user_id, chirp_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: DeleteRechirp :execrows
+DELETE FROM rechirps
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: CountRechirps :one
+SELECT COUNT(*) FROM rechirps
+WHERE chirp_id = $1;
+
+-- name: CountRechirpsByChirpIDs :many
+SELECT chirp_id, COUNT(*) FROM rechirps
+WHERE chirp_id = ANY($1::uuid[])
+GROUP BY chirp_id;
@@ -0,0 +1,25 @@
+-- name: CreateReport :execrows
+INSERT INTO reports (id, chirp_id, reporter_id, reason, details, status, created_at, reporter_addr)
+VALUES ($1, $2, $3, $4, $5, 'open', $6, $7)
+ON CONFLICT (chirp_id, reporter_id) DO NOTHING;
+
+-- name: GetReport :one
+SELECT r.* FROM reports r
+JOIN chirps c ON c.id = r.chirp_id
+WHERE r.id = $1 AND c.tenant_id = $2;
+
+-- name: CountOpenReportAddresses :one
+SELECT COUNT(DISTINCT reporter_addr) FROM reports
+WHERE chirp_id = $1 AND status = 'open';
+
+-- name: ListReports :many
+SELECT r.* FROM reports r
+JOIN chirps c ON c.id = r.chirp_id
+WHERE r.status = sqlc.arg(status) AND c.tenant_id = sqlc.arg(tenant_id)
+  AND (r.created_at, r.id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+ORDER BY r.created_at, r.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ReviewChirpReports :execrows
+UPDATE reports SET status = $2, reviewed_at = $3
+WHERE chirp_id = $1 AND status = 'open';
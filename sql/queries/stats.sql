@@ -0,0 +1,23 @@
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE tenant_id = $1;
+
+-- name: CountChirps :one
+SELECT COUNT(*) FROM chirps
+WHERE tenant_id = $1;
+
+-- name: GetDailyChirpCounts :many
+SELECT date_trunc('day', created_at)::timestamp AS day, COUNT(*) AS chirps
+FROM chirps
+WHERE tenant_id = $1 AND created_at >= $2
+GROUP BY day
+ORDER BY day;
+
+-- name: GetTopAuthors :many
+SELECT chirps.user_id, users.email, COUNT(*) AS chirps
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.tenant_id = $1 AND chirps.created_at >= $2
+GROUP BY chirps.user_id, users.email
+ORDER BY chirps DESC
+LIMIT $3;
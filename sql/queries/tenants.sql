@@ -0,0 +1,16 @@
+-- name: CreateTenant :one
+INSERT INTO tenants (id, slug, name, host, created_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetTenantByHost :one
+SELECT * FROM tenants
+WHERE host = $1;
+
+-- name: GetTenantBySlug :one
+SELECT * FROM tenants
+WHERE slug = $1;
+
+-- name: ListTenants :many
+SELECT * FROM tenants
+ORDER BY slug;
@@ -0,0 +1,14 @@
+-- name: CreateUserStrike :exec
+INSERT INTO user_strikes (id, user_id, reason, details, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetActiveStrikeSummary :one
+SELECT COUNT(*) AS active, COALESCE(MAX(created_at), '0001-01-01')::timestamp AS last_strike_at
+FROM user_strikes
+WHERE user_id = $1 AND expires_at > $2;
+
+-- name: ListUserStrikes :many
+SELECT * FROM user_strikes
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
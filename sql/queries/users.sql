@@ -1,7 +1,22 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email)
-VALUES ($1, $2, $3, $4)
+INSERT INTO users (id, created_at, updated_at, email, posting_secret, tenant_id)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: DeleteAllUsers :exec
-DELETE FROM users;
+DELETE FROM users
+WHERE tenant_id = $1;
+
+-- name: GetUserByPostingSecret :one
+SELECT * FROM users
+WHERE posting_secret = $1;
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: UpdatePostingSecret :one
+UPDATE users
+SET posting_secret = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,20 @@
+// Package sql embeds the goose migrations in sql/schema, so the binaries
+// that check and apply them don't need the files on disk.
+package sql
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed schema/*.sql
+var files embed.FS
+
+// Schema returns the Postgres migrations, one file per goose version
+func Schema() fs.FS {
+	sub, err := fs.Sub(files, "schema")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
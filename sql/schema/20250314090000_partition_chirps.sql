@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE chirps RENAME TO chirps_unpartitioned;
+
+CREATE TABLE chirps (
+    id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    body TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+-- Rows outside any monthly partition land here until the maintenance job catches up
+CREATE TABLE chirps_default PARTITION OF chirps DEFAULT;
+
+CREATE INDEX chirps_user_id_created_at_idx ON chirps (user_id, created_at DESC);
+CREATE INDEX chirps_created_at_idx ON chirps (created_at DESC);
+
+INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+SELECT id, created_at, updated_at, body, user_id FROM chirps_unpartitioned;
+
+DROP TABLE chirps_unpartitioned;
+
+-- +goose Down
+CREATE TABLE chirps_unpartitioned (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    body TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO chirps_unpartitioned (id, created_at, updated_at, body, user_id)
+SELECT id, created_at, updated_at, body, user_id FROM chirps;
+
+DROP TABLE chirps;
+ALTER TABLE chirps_unpartitioned RENAME TO chirps;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- chirp_id has no foreign key because chirps is partitioned on created_at
+CREATE TABLE rechirps (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX rechirps_chirp_id_idx ON rechirps (chirp_id);
+
+-- +goose Down
+DROP TABLE rechirps;
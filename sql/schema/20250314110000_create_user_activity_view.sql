@@ -0,0 +1,9 @@
+-- +goose Up
+-- user_activity is every action that counts a user as active on a given day
+CREATE VIEW user_activity AS
+SELECT user_id, created_at FROM chirps
+UNION ALL
+SELECT user_id, created_at FROM rechirps;
+
+-- +goose Down
+DROP VIEW user_activity;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE link_previews (
+    url TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE link_previews;
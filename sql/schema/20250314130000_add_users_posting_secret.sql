@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN posting_secret TEXT NOT NULL UNIQUE DEFAULT replace(gen_random_uuid()::text, '-', '');
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN posting_secret;
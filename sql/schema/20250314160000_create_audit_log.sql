@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX audit_log_created_at_id_idx ON audit_log (created_at DESC, id DESC);
+CREATE INDEX audit_log_action_idx ON audit_log (action);
+CREATE INDEX audit_log_target_idx ON audit_log (target);
+
+-- +goose Down
+DROP TABLE audit_log;
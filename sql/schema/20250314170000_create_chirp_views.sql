@@ -0,0 +1,21 @@
+-- +goose Up
+-- chirp_id has no foreign key because chirps is partitioned on created_at
+CREATE TABLE chirp_views (
+    chirp_id UUID NOT NULL,
+    viewer TEXT NOT NULL,
+    day DATE NOT NULL,
+    counted BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (chirp_id, viewer, day)
+);
+
+CREATE INDEX chirp_views_uncounted_idx ON chirp_views (chirp_id) WHERE NOT counted;
+CREATE INDEX chirp_views_day_idx ON chirp_views (day);
+
+CREATE TABLE chirp_view_counts (
+    chirp_id UUID PRIMARY KEY,
+    view_count BIGINT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE chirp_view_counts;
+DROP TABLE chirp_views;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE user_strikes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX user_strikes_user_id_expires_at_idx ON user_strikes (user_id, expires_at);
+
+-- +goose Down
+DROP TABLE user_strikes;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- The author of each chirp as they were when it was posted, for permalinks.
+-- chirp_id has no foreign key because chirps is partitioned on created_at.
+CREATE TABLE chirp_authors (
+    chirp_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    captured_at TIMESTAMP NOT NULL
+);
+
+-- Existing chirps get their author as they are now
+INSERT INTO chirp_authors (chirp_id, user_id, email, captured_at)
+SELECT c.id, c.user_id, u.email, c.created_at
+FROM chirps c
+JOIN users u ON u.id = c.user_id;
+
+-- +goose Down
+DROP TABLE chirp_authors;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- chirp_id has no foreign key because chirps is partitioned on created_at
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    created_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP,
+    UNIQUE (chirp_id, reporter_id)
+);
+
+CREATE INDEX reports_status_created_at_id_idx ON reports (status, created_at, id);
+
+-- Chirps hidden from public reads, e.g. while their reports are reviewed
+CREATE TABLE chirp_holds (
+    chirp_id UUID PRIMARY KEY,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE chirp_holds;
+DROP TABLE reports;
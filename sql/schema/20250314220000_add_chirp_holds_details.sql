@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE chirp_holds ADD COLUMN details TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX chirp_holds_reason_created_at_idx ON chirp_holds (reason, created_at, chirp_id);
+
+-- +goose Down
+DROP INDEX chirp_holds_reason_created_at_idx;
+ALTER TABLE chirp_holds DROP COLUMN details;
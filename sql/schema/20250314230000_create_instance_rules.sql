@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE instance_rules (
+    position INT PRIMARY KEY,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+-- The reasons a chirp can be reported for, in the order they're shown
+CREATE TABLE report_reasons (
+    code TEXT PRIMARY KEY,
+    label TEXT NOT NULL,
+    position INT NOT NULL
+);
+
+INSERT INTO report_reasons (code, label, position) VALUES
+    ('spam', 'Spam', 0),
+    ('harassment', 'Harassment', 1),
+    ('hate', 'Hate speech', 2),
+    ('violence', 'Violence', 3),
+    ('self_harm', 'Self-harm', 4),
+    ('other', 'Other', 5);
+
+-- +goose Down
+DROP TABLE report_reasons;
+DROP TABLE instance_rules;
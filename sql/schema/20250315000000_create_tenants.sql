@@ -0,0 +1,34 @@
+-- +goose Up
+-- A tenant is one chirp community. Requests pick theirs by host name or the
+-- X-Chirpy-Tenant header.
+CREATE TABLE tenants (
+    id UUID PRIMARY KEY,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    host TEXT UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- The default tenant owns everything from before tenants existed and serves
+-- any host no other tenant claims
+INSERT INTO tenants (id, slug, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default', 'Default', now());
+
+ALTER TABLE users
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+ALTER TABLE users ADD CONSTRAINT users_tenant_id_email_key UNIQUE (tenant_id, email);
+
+ALTER TABLE chirps
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+CREATE INDEX chirps_tenant_id_created_at_idx ON chirps (tenant_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX chirps_tenant_id_created_at_idx;
+ALTER TABLE chirps DROP COLUMN tenant_id;
+
+ALTER TABLE users DROP CONSTRAINT users_tenant_id_email_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users DROP COLUMN tenant_id;
+
+DROP TABLE tenants;
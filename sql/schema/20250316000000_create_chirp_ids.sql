@@ -0,0 +1,68 @@
+-- +goose Up
+-- chirps is partitioned on created_at, so its primary key is (id,
+-- created_at) and neither keeps ids unique nor can be referenced. chirp_ids
+-- holds every chirp's id, kept in step by triggers, so ids stay unique and
+-- the tables keyed by chirp_id lose their rows along with the chirp.
+CREATE TABLE chirp_ids (
+    id UUID PRIMARY KEY
+);
+
+INSERT INTO chirp_ids (id)
+SELECT id FROM chirps;
+
+-- +goose StatementBegin
+CREATE FUNCTION chirp_ids_sync() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO chirp_ids (id) VALUES (NEW.id);
+    -- Partition maintenance moves rows out of chirps_default and marks
+    -- the move so the chirps keep their ids
+    ELSIF current_setting('chirpy.moving_chirps', true) IS DISTINCT FROM 'on' THEN
+        DELETE FROM chirp_ids WHERE id = OLD.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER chirp_ids_insert AFTER INSERT ON chirps
+    FOR EACH ROW EXECUTE FUNCTION chirp_ids_sync();
+CREATE TRIGGER chirp_ids_delete AFTER DELETE ON chirps
+    FOR EACH ROW EXECUTE FUNCTION chirp_ids_sync();
+
+-- Rows left behind by chirps deleted before now
+DELETE FROM rechirps WHERE chirp_id NOT IN (SELECT id FROM chirp_ids);
+DELETE FROM chirp_views WHERE chirp_id NOT IN (SELECT id FROM chirp_ids);
+DELETE FROM chirp_view_counts WHERE chirp_id NOT IN (SELECT id FROM chirp_ids);
+DELETE FROM polls WHERE chirp_id NOT IN (SELECT id FROM chirp_ids);
+DELETE FROM chirp_authors WHERE chirp_id NOT IN (SELECT id FROM chirp_ids);
+DELETE FROM reports WHERE chirp_id NOT IN (SELECT id FROM chirp_ids);
+DELETE FROM chirp_holds WHERE chirp_id NOT IN (SELECT id FROM chirp_ids);
+
+ALTER TABLE rechirps ADD CONSTRAINT rechirps_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirp_ids(id) ON DELETE CASCADE;
+ALTER TABLE chirp_views ADD CONSTRAINT chirp_views_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirp_ids(id) ON DELETE CASCADE;
+ALTER TABLE chirp_view_counts ADD CONSTRAINT chirp_view_counts_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirp_ids(id) ON DELETE CASCADE;
+ALTER TABLE polls ADD CONSTRAINT polls_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirp_ids(id) ON DELETE CASCADE;
+ALTER TABLE chirp_authors ADD CONSTRAINT chirp_authors_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirp_ids(id) ON DELETE CASCADE;
+ALTER TABLE reports ADD CONSTRAINT reports_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirp_ids(id) ON DELETE CASCADE;
+ALTER TABLE chirp_holds ADD CONSTRAINT chirp_holds_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirp_ids(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE chirp_holds DROP CONSTRAINT chirp_holds_chirp_id_fkey;
+ALTER TABLE reports DROP CONSTRAINT reports_chirp_id_fkey;
+ALTER TABLE chirp_authors DROP CONSTRAINT chirp_authors_chirp_id_fkey;
+ALTER TABLE polls DROP CONSTRAINT polls_chirp_id_fkey;
+ALTER TABLE chirp_view_counts DROP CONSTRAINT chirp_view_counts_chirp_id_fkey;
+ALTER TABLE chirp_views DROP CONSTRAINT chirp_views_chirp_id_fkey;
+ALTER TABLE rechirps DROP CONSTRAINT rechirps_chirp_id_fkey;
+DROP TRIGGER chirp_ids_delete ON chirps;
+DROP TRIGGER chirp_ids_insert ON chirps;
+DROP FUNCTION chirp_ids_sync();
+DROP TABLE chirp_ids;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Idempotency keys are per tenant, so clients of two tenants can pick the
+-- same key without one replaying the other's response. Keys from before
+-- belong to the default tenant.
+ALTER TABLE idempotency_keys
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key, endpoint);
+
+-- +goose Down
+DELETE FROM idempotency_keys
+WHERE tenant_id <> '00000000-0000-0000-0000-000000000000';
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key, endpoint);
+ALTER TABLE idempotency_keys DROP COLUMN tenant_id;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Reporters name themselves by an unauthenticated user_id, so only distinct
+-- client addresses count toward hiding a chirp. Earlier reports have no
+-- address and count once each.
+ALTER TABLE reports ADD COLUMN reporter_addr TEXT;
+UPDATE reports SET reporter_addr = reporter_id::text;
+ALTER TABLE reports ALTER COLUMN reporter_addr SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE reports DROP COLUMN reporter_addr;
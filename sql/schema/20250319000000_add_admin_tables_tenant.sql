@@ -0,0 +1,55 @@
+-- +goose Up
+-- Instance rules, report reasons, the audit log and jobs belong to a
+-- tenant, so each community's admins only see and change their own. Rows
+-- from before belong to the default tenant. A tenant with no report reasons
+-- of its own uses the default tenant's.
+ALTER TABLE instance_rules
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+ALTER TABLE instance_rules DROP CONSTRAINT instance_rules_pkey;
+ALTER TABLE instance_rules ADD PRIMARY KEY (tenant_id, position);
+
+ALTER TABLE report_reasons
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+ALTER TABLE report_reasons DROP CONSTRAINT report_reasons_pkey;
+ALTER TABLE report_reasons ADD PRIMARY KEY (tenant_id, code);
+
+ALTER TABLE audit_log
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+DROP INDEX audit_log_created_at_id_idx;
+CREATE INDEX audit_log_tenant_id_created_at_id_idx ON audit_log (tenant_id, created_at DESC, id DESC);
+
+ALTER TABLE jobs
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id);
+
+-- Rechirps take their tenant from the user, who can only rechirp in their own
+CREATE OR REPLACE VIEW user_activity AS
+SELECT user_id, created_at, tenant_id FROM chirps
+UNION ALL
+SELECT rechirps.user_id, rechirps.created_at, users.tenant_id
+FROM rechirps
+JOIN users ON users.id = rechirps.user_id;
+
+-- +goose Down
+DROP VIEW user_activity;
+CREATE VIEW user_activity AS
+SELECT user_id, created_at FROM chirps
+UNION ALL
+SELECT user_id, created_at FROM rechirps;
+
+ALTER TABLE jobs DROP COLUMN tenant_id;
+
+DROP INDEX audit_log_tenant_id_created_at_id_idx;
+CREATE INDEX audit_log_created_at_id_idx ON audit_log (created_at DESC, id DESC);
+ALTER TABLE audit_log DROP COLUMN tenant_id;
+
+DELETE FROM report_reasons
+WHERE tenant_id <> '00000000-0000-0000-0000-000000000000';
+ALTER TABLE report_reasons DROP CONSTRAINT report_reasons_pkey;
+ALTER TABLE report_reasons ADD PRIMARY KEY (code);
+ALTER TABLE report_reasons DROP COLUMN tenant_id;
+
+DELETE FROM instance_rules
+WHERE tenant_id <> '00000000-0000-0000-0000-000000000000';
+ALTER TABLE instance_rules DROP CONSTRAINT instance_rules_pkey;
+ALTER TABLE instance_rules ADD PRIMARY KEY (position);
+ALTER TABLE instance_rules DROP COLUMN tenant_id;
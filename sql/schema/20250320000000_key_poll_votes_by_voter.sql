@@ -0,0 +1,19 @@
+-- +goose Up
+-- Votes are counted once per client IP, like views, since the user_id a
+-- vote came with isn't verified. Existing votes keep their user as voter.
+ALTER TABLE poll_votes ADD COLUMN voter TEXT;
+UPDATE poll_votes SET voter = 'user:' || user_id;
+ALTER TABLE poll_votes ALTER COLUMN voter SET NOT NULL;
+ALTER TABLE poll_votes DROP CONSTRAINT poll_votes_pkey;
+ALTER TABLE poll_votes ADD PRIMARY KEY (chirp_id, voter);
+ALTER TABLE poll_votes DROP COLUMN user_id;
+
+-- +goose Down
+ALTER TABLE poll_votes ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+UPDATE poll_votes SET user_id = substr(voter, 6)::uuid WHERE voter LIKE 'user:%';
+DELETE FROM poll_votes WHERE user_id IS NULL;
+ALTER TABLE poll_votes ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE poll_votes DROP CONSTRAINT poll_votes_pkey;
+ALTER TABLE poll_votes ADD PRIMARY KEY (chirp_id, user_id);
+ALTER TABLE poll_votes DROP COLUMN voter;
+CREATE INDEX poll_votes_user_id_idx ON poll_votes (user_id);
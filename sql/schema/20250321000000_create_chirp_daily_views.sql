@@ -0,0 +1,17 @@
+-- +goose Up
+-- Views per chirp per day for author analytics, so the per-client rows in
+-- chirp_views can be deleted once their day is over and rolled up
+CREATE TABLE chirp_daily_views (
+    chirp_id UUID NOT NULL REFERENCES chirp_ids(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views BIGINT NOT NULL,
+    PRIMARY KEY (chirp_id, day)
+);
+
+INSERT INTO chirp_daily_views (chirp_id, day, views)
+SELECT chirp_id, day, COUNT(*) FROM chirp_views
+WHERE counted
+GROUP BY chirp_id, day;
+
+-- +goose Down
+DROP TABLE chirp_daily_views;
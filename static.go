@@ -0,0 +1,93 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+//go:embed web
+var webFS embed.FS
+
+// webFiles returns the web client to serve: the directory root, or the
+// embedded client if root is empty
+func webFiles(root string) http.FileSystem {
+	if root != "" {
+		return http.Dir(root)
+	}
+	sub, err := fs.Sub(webFS, "web")
+	if err != nil {
+		panic(err)
+	}
+	return http.FS(sub)
+}
+
+// staticHandler serves the web client from files. Dotfiles and directory
+// listings are never served, and paths that look like client-side routes
+// (no file extension) fall back to index.html. HTML is revalidated on every
+// load so a deploy is picked up at once; other assets are cached for
+// maxAge.
+func staticHandler(files http.FileSystem, maxAge time.Duration) http.Handler {
+	fileServer := http.FileServer(files)
+	assetCache := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+		for _, part := range strings.Split(name, "/") {
+			if strings.HasPrefix(part, ".") {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		isDir, err := statFile(files, name)
+		switch {
+		case err == nil && !isDir:
+			if path.Ext(name) == ".html" {
+				w.Header().Set("Cache-Control", "no-cache")
+			} else {
+				w.Header().Set("Cache-Control", assetCache)
+			}
+		case err == nil:
+			// A directory is served by its index.html, never as a listing
+			if _, err := statFile(files, path.Join(name, "index.html")); err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Cache-Control", "no-cache")
+		case errors.Is(err, fs.ErrNotExist) && path.Ext(name) == "":
+			// Client-side routes get the app shell
+			if _, err := statFile(files, "/index.html"); err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Cache-Control", "no-cache")
+			r.URL.Path = "/"
+			r.URL.RawPath = ""
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// statFile reports whether name in files is a directory
+func statFile(files http.FileSystem, name string) (bool, error) {
+	f, err := files.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	return info.IsDir(), nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaticFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"index.html":    "<h1>app</h1>",
+		"assets/app.js": "console.log(1)",
+		".env":          "SECRET=1",
+		".git/config":   "[core]",
+	}
+	for name, body := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := testConfig()
+	cfg.Web.Root = root
+	cfg.Web.CacheMaxAge = time.Hour
+	srv := newTestServer(t, cfg)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+		wantCache  string
+	}{
+		{"index", "/app/", http.StatusOK, "<h1>app</h1>", "no-cache"},
+		{"asset", "/app/assets/app.js", http.StatusOK, "console.log(1)", "public, max-age=3600"},
+		{"client route", "/app/chirps/123", http.StatusOK, "<h1>app</h1>", "no-cache"},
+		{"missing asset", "/app/assets/missing.js", http.StatusNotFound, "", ""},
+		{"dotfile", "/app/.env", http.StatusNotFound, "", ""},
+		{"dot directory", "/app/.git/config", http.StatusNotFound, "", ""},
+		{"no listing", "/app/assets/", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := srv.do(http.MethodGet, tt.path, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantBody != "" && !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want %q", rec.Body, tt.wantBody)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestEmbeddedWebClient(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	for _, path := range []string{"/app/", "/app/chirps/123", "/app/assets/app.js", "/app/assets/app.css"} {
+		if rec := srv.do(http.MethodGet, path, ""); rec.Code != http.StatusOK {
+			t.Errorf("%s status = %d, want 200", path, rec.Code)
+		}
+	}
+	if rec := srv.do(http.MethodGet, "/app/chirps/123", ""); !strings.Contains(rec.Body.String(), "<title>Chirpy</title>") {
+		t.Errorf("client route body = %q, want the app shell", rec.Body)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// Strike reasons. Filtered words don't earn a strike: the author of a
+// chirp is whoever the body names, so anyone could strike anyone.
+const (
+	strikeRejectedChirp = "rejected_chirp"
+	strikeUpheldReport  = "upheld_report"
+)
+
+// Standing levels, from least to most severe
+const (
+	standingGood      = "good_standing"
+	standingWarned    = "warned"
+	standingCooldown  = "cooldown"
+	standingSuspended = "suspended"
+)
+
+// adminRecentStrikes is how many strikes the admin user view lists
+const adminRecentStrikes = 20
+
+// errSuspended is returned when a suspended user tries to post
+var errSuspended = errors.New("account is suspended")
+
+// postingCooldownError is returned when a user in a cooldown tries to post
+type postingCooldownError struct {
+	until time.Time
+}
+
+func (e *postingCooldownError) Error() string {
+	return fmt.Sprintf("posting is paused until %s", e.until.Format(time.RFC3339))
+}
+
+// strikeStatus is a user's standing, worked out from their active strikes
+type strikeStatus struct {
+	Level         string     `json:"level"`
+	ActiveStrikes int64      `json:"active_strikes"`
+	CooldownUntil *time.Time `json:"cooldown_until,omitempty"`
+}
+
+// strikeResponse represents one strike in the admin user view
+type strikeResponse struct {
+	ID        string    `json:"id"`
+	Reason    string    `json:"reason"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// adminUserResponse represents a user in the admin user view
+type adminUserResponse struct {
+	userResponse
+	Standing *strikeStatus    `json:"standing,omitempty"`
+	Strikes  []strikeResponse `json:"strikes,omitempty"`
+}
+
+// addStrike records a violation against a user. Strikes stop counting once
+// the configured decay has passed.
+func (cfg *apiConfig) addStrike(ctx context.Context, userID uuid.UUID, reason, details string) error {
+	if cfg.db == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	return cfg.db.CreateUserStrike(ctx, database.CreateUserStrikeParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Reason:    reason,
+		Details:   details,
+		CreatedAt: now,
+		ExpiresAt: now.Add(cfg.strikes.Decay),
+	})
+}
+
+// strikeStatus works out a user's standing from their active strikes.
+// Without Postgres every user is in good standing.
+func (cfg *apiConfig) strikeStatus(ctx context.Context, userID uuid.UUID) (strikeStatus, error) {
+	status := strikeStatus{Level: standingGood}
+	if cfg.db == nil {
+		return status, nil
+	}
+
+	now := time.Now().UTC()
+	summary, err := cfg.db.GetActiveStrikeSummary(ctx, database.GetActiveStrikeSummaryParams{
+		UserID:    userID,
+		ExpiresAt: now,
+	})
+	if err != nil {
+		return status, err
+	}
+	return standing(cfg.strikes, summary.Active, summary.LastStrikeAt, now), nil
+}
+
+// standing maps a count of active strikes and the time of the latest one to
+// a standing level
+func standing(cfg config.Strikes, active int64, lastStrikeAt, now time.Time) strikeStatus {
+	status := strikeStatus{Level: standingGood, ActiveStrikes: active}
+	switch n := int(active); {
+	case n >= cfg.SuspendAt:
+		status.Level = standingSuspended
+	case n >= cfg.CooldownAt:
+		// The cooldown runs from the latest strike; once it's over the
+		// user is back to a warning until enough strikes decay
+		status.Level = standingWarned
+		if until := lastStrikeAt.Add(cfg.Cooldown); until.After(now) {
+			status.Level = standingCooldown
+			status.CooldownUntil = &until
+		}
+	case n >= cfg.WarnAt:
+		status.Level = standingWarned
+	}
+	return status
+}
+
+// checkCanPost returns errSuspended or a *postingCooldownError if the user
+// may not post right now
+func (cfg *apiConfig) checkCanPost(ctx context.Context, userID uuid.UUID) error {
+	status, err := cfg.strikeStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	switch status.Level {
+	case standingSuspended:
+		return errSuspended
+	case standingCooldown:
+		return &postingCooldownError{until: *status.CooldownUntil}
+	}
+	return nil
+}
+
+// strikeWarning returns the warning shown to a user who has active strikes,
+// or "" if they're in good standing
+func (cfg *apiConfig) strikeWarning(ctx context.Context, userID uuid.UUID) string {
+	status, err := cfg.strikeStatus(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get strike status for user %s: %v", userID, err)
+		return ""
+	}
+	if status.Level == standingGood {
+		return ""
+	}
+	return fmt.Sprintf("Your account has %d active strike(s) for breaking the content rules. Posting is paused at %d and the account is suspended at %d.",
+		status.ActiveStrikes, cfg.strikes.CooldownAt, cfg.strikes.SuspendAt)
+}
+
+// respondWithStrikeError writes the response for a user who may not post,
+// and reports whether err was one of the strike errors
+func respondWithStrikeError(w http.ResponseWriter, err error) bool {
+	var cooldown *postingCooldownError
+	switch {
+	case errors.Is(err, errSuspended):
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Your account is suspended", Code: "suspended"})
+		return true
+	case errors.As(err, &cooldown):
+		retryAfter := int(time.Until(cooldown.until).Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Posting is paused for your account", Code: "posting_cooldown"})
+		return true
+	}
+	return false
+}
+
+// adminUserHandler shows a user along with their standing and recent strikes
+func (cfg *apiConfig) adminUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := request.ParseUUIDParam(r, "userID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	user, err := cfg.store.GetUser(r.Context(), database.GetUserParams{ID: userID, TenantID: tenantID(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "User not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get user"})
+		return
+	}
+
+	resp := adminUserResponse{
+		userResponse: userResponse{
+			ID:        user.ID.String(),
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+			Email:     user.Email,
+		},
+	}
+
+	// Strikes need Postgres
+	if cfg.db != nil {
+		status, err := cfg.strikeStatus(r.Context(), userID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get strikes"})
+			return
+		}
+		strikes, err := cfg.db.ListUserStrikes(r.Context(), database.ListUserStrikesParams{
+			UserID: userID,
+			Limit:  adminRecentStrikes,
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get strikes"})
+			return
+		}
+		resp.Standing = &status
+		resp.Strikes = []strikeResponse{}
+		for _, s := range strikes {
+			resp.Strikes = append(resp.Strikes, strikeResponse{
+				ID:        s.ID.String(),
+				Reason:    s.Reason,
+				Details:   s.Details,
+				CreatedAt: s.CreatedAt,
+				ExpiresAt: s.ExpiresAt,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+)
+
+func TestStanding(t *testing.T) {
+	cfg := config.Strikes{WarnAt: 1, CooldownAt: 3, Cooldown: time.Hour, SuspendAt: 5}
+	now := time.Now()
+	tests := []struct {
+		name      string
+		active    int64
+		last      time.Time
+		wantLevel string
+	}{
+		{"no strikes", 0, time.Time{}, standingGood},
+		{"warned", 1, now, standingWarned},
+		{"cooldown", 3, now.Add(-time.Minute), standingCooldown},
+		{"cooldown over", 4, now.Add(-2 * time.Hour), standingWarned},
+		{"suspended", 5, now.Add(-2 * time.Hour), standingSuspended},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := standing(cfg, tt.active, tt.last, now)
+			if got.Level != tt.wantLevel {
+				t.Errorf("level = %q, want %q", got.Level, tt.wantLevel)
+			}
+			if (got.Level == standingCooldown) != (got.CooldownUntil != nil) {
+				t.Errorf("cooldown_until = %v for level %q", got.CooldownUntil, got.Level)
+			}
+		})
+	}
+}
+
+func TestAdminUser(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	user := srv.createUser("a@example.com")
+
+	rec := srv.do(http.MethodGet, "/admin/users/"+user.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	got := decode[adminUserResponse](t, rec)
+	if got.Email != user.Email {
+		t.Errorf("email = %q, want %q", got.Email, user.Email)
+	}
+	// Strikes need Postgres, so the memory store shows none
+	if got.Standing != nil || got.Strikes != nil {
+		t.Errorf("standing = %v, strikes = %v, want none", got.Standing, got.Strikes)
+	}
+
+	if rec := srv.do(http.MethodGet, "/admin/users/00000000-0000-0000-0000-000000000000", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown user status = %d, want 404", rec.Code)
+	}
+	if rec := srv.do(http.MethodGet, "/admin/users/nope", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad id status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"embed"
+	"html/template"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// templates holds the parsed admin UI templates
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tenantHeader picks a tenant by slug on hosts no tenant claims
+const tenantHeader = "X-Chirpy-Tenant"
+
+// Resolved tenants are cached so most requests don't look them up. A new
+// tenant or host is picked up once the cached entry expires.
+const (
+	tenantCacheSize = 1000
+	tenantCacheTTL  = time.Minute
+)
+
+// defaultTenantSlug is the slug of store.DefaultTenantID
+const defaultTenantSlug = "default"
+
+// errUnknownTenant is returned when the tenant header names no tenant
+var errUnknownTenant = errors.New("unknown tenant")
+
+// errTenantMismatch is returned when the tenant header names a different
+// tenant than the one serving the request's host
+var errTenantMismatch = errors.New("tenant header doesn't match the host")
+
+// tenantKey is the context key for the request's tenant
+type tenantKey struct{}
+
+// requestTenant returns the tenant stored by middlewareTenant, or the
+// default tenant for requests it didn't see
+func requestTenant(ctx context.Context) database.Tenant {
+	if tenant, ok := ctx.Value(tenantKey{}).(database.Tenant); ok {
+		return tenant
+	}
+	return database.Tenant{ID: store.DefaultTenantID, Slug: defaultTenantSlug}
+}
+
+// tenantID returns the ID of the request's tenant
+func tenantID(ctx context.Context) uuid.UUID {
+	return requestTenant(ctx).ID
+}
+
+// middlewareTenant resolves the request's tenant before calling next
+func (cfg *apiConfig) middlewareTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, err := cfg.resolveTenant(r)
+		if errors.Is(err, errUnknownTenant) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Unknown tenant"})
+			return
+		}
+		if errors.Is(err, errTenantMismatch) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(errorResponse{Error: "X-Chirpy-Tenant doesn't match the host's tenant", Code: "tenant_mismatch"})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: "Failed to resolve tenant"})
+			return
+		}
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("chirpy.tenant", tenant.Slug))
+		ctx := context.WithValue(r.Context(), tenantKey{}, tenant)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// resolveTenant finds the tenant serving the request's host. The tenant
+// header can only pick a tenant on hosts no tenant claims, so a tenant's
+// domain never serves another tenant's data; elsewhere it must agree with
+// the host. Requests with neither get the default tenant.
+func (cfg *apiConfig) resolveTenant(r *http.Request) (database.Tenant, error) {
+	ctx := r.Context()
+	slug := strings.TrimSpace(r.Header.Get(tenantHeader))
+	tenant, err := cfg.tenantByHost(ctx, requestHost(r))
+	if err == nil {
+		if slug != "" && slug != tenant.Slug {
+			return database.Tenant{}, errTenantMismatch
+		}
+		return tenant, nil
+	}
+	if !errors.Is(err, errUnknownTenant) {
+		return database.Tenant{}, err
+	}
+
+	if slug == "" {
+		slug = defaultTenantSlug
+	}
+	return cfg.tenantBySlug(ctx, slug)
+}
+
+// tenantByHost finds the tenant serving host, or returns errUnknownTenant.
+// Hosts no tenant claims are cached too, as a tenant with no ID.
+func (cfg *apiConfig) tenantByHost(ctx context.Context, host string) (database.Tenant, error) {
+	key := "host:" + host
+	tenant, ok := cfg.tenants.Get(key)
+	if !ok {
+		var err error
+		tenant, err = cfg.store.GetTenantByHost(ctx, sql.NullString{String: host, Valid: true})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return database.Tenant{}, err
+		}
+		cfg.tenants.Set(key, tenant)
+	}
+	if tenant.ID == uuid.Nil {
+		return database.Tenant{}, errUnknownTenant
+	}
+	return tenant, nil
+}
+
+// tenantBySlug finds the tenant with slug, or returns errUnknownTenant
+func (cfg *apiConfig) tenantBySlug(ctx context.Context, slug string) (database.Tenant, error) {
+	key := "slug:" + slug
+	if tenant, ok := cfg.tenants.Get(key); ok {
+		return tenant, nil
+	}
+	tenant, err := cfg.store.GetTenantBySlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Tenant{}, errUnknownTenant
+	}
+	if err != nil {
+		return database.Tenant{}, err
+	}
+	cfg.tenants.Set(key, tenant)
+	return tenant, nil
+}
+
+// requestHost returns the request's host name, lowercased and without a
+// port or trailing dot
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
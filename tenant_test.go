@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+	club, err := srv.store.CreateTenant(context.Background(), database.CreateTenantParams{
+		ID:        uuid.New(),
+		Slug:      "club",
+		Name:      "Book Club",
+		Host:      sql.NullString{String: "club.example.com", Valid: true},
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same email can sign up in each tenant
+	outsider := srv.createUser("reader@example.com")
+	rec := srv.do(http.MethodPost, "/api/users", `{"email":"reader@example.com"}`, tenantHeader, "club")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating club user: %d %s", rec.Code, rec.Body)
+	}
+	member := decode[userResponse](t, rec)
+
+	rec = srv.do(http.MethodPost, "/api/chirps", `{"body":"hello","user_id":"`+member.ID+`"}`, tenantHeader, "club")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating club chirp: %d %s", rec.Code, rec.Body)
+	}
+	chirp := decode[chirpResponse](t, rec)
+
+	// The chirp is found by header or host, but not from the default tenant
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, "", tenantHeader, "club"); rec.Code != http.StatusOK {
+		t.Errorf("by header: status = %d, want 200", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/chirps/"+chirp.ID, nil)
+	req.Host = "Club.Example.com:8080"
+	rec = httptest.NewRecorder()
+	srv.handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("by host: status = %d, want 200", rec.Code)
+	}
+	if rec := srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("from default tenant: status = %d, want 404", rec.Code)
+	}
+
+	// A tenant's host wins over the header
+	req = httptest.NewRequest(http.MethodGet, "/api/chirps/"+chirp.ID, nil)
+	req.Host = "club.example.com"
+	req.Header.Set(tenantHeader, "default")
+	rec = httptest.NewRecorder()
+	srv.handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || decode[errorResponse](t, rec).Code != "tenant_mismatch" {
+		t.Errorf("header against host: status = %d, want 400 tenant_mismatch (%s)", rec.Code, rec.Body)
+	}
+
+	// Users can't post or rechirp in another tenant
+	rec = srv.do(http.MethodPost, "/api/chirps", `{"body":"hi","user_id":"`+member.ID+`"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("posting across tenants: status = %d, want 404 (%s)", rec.Code, rec.Body)
+	}
+	rec = srv.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/rechirp", `{"user_id":"`+outsider.ID+`"}`, tenantHeader, "club")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("rechirping across tenants: status = %d, want 404 (%s)", rec.Code, rec.Body)
+	}
+
+	// Admin stats count each tenant's own users and chirps
+	tests := []struct {
+		slug       string
+		wantUsers  int64
+		wantChirps int64
+	}{
+		{"default", 1, 0},
+		{"club", 1, 1},
+	}
+	for _, tt := range tests {
+		rec := srv.do(http.MethodGet, "/admin/stats", "", tenantHeader, tt.slug)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s stats: status = %d (%s)", tt.slug, rec.Code, rec.Body)
+		}
+		got := decode[adminStats](t, rec)
+		if got.Tenant != tt.slug || got.TotalUsers != tt.wantUsers || got.TotalChirps != tt.wantChirps {
+			t.Errorf("%s stats = %s with %d users and %d chirps, want %d and %d",
+				tt.slug, got.Tenant, got.TotalUsers, got.TotalChirps, tt.wantUsers, tt.wantChirps)
+		}
+	}
+
+	// Resetting a tenant leaves the other tenants' users alone
+	if rec := srv.do(http.MethodPost, "/admin/reset", "", tenantHeader, "club"); rec.Code != http.StatusOK {
+		t.Fatalf("reset: status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	for id, want := range map[uuid.UUID]int64{club.ID: 0, store.DefaultTenantID: 1} {
+		if users, err := srv.store.CountUsers(context.Background(), id); err != nil || users != want {
+			t.Errorf("users in %s after reset = %d (%v), want %d", id, users, err, want)
+		}
+	}
+	if chirps, _ := srv.store.CountChirps(context.Background(), club.ID); chirps != 0 {
+		t.Errorf("club chirps after reset = %d, want 0", chirps)
+	}
+}
+
+func TestUnknownTenant(t *testing.T) {
+	srv := newTestServer(t, testConfig())
+
+	rec := srv.do(http.MethodGet, "/admin/stats", "", tenantHeader, "nope")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404 (%s)", rec.Code, rec.Body)
+	}
+	if got := decode[errorResponse](t, rec); got.Error != "Unknown tenant" {
+		t.Errorf("error = %q, want %q", got.Error, "Unknown tenant")
+	}
+
+	// Probes don't depend on a tenant
+	if rec := srv.do(http.MethodGet, "/healthz", "", tenantHeader, "nope"); rec.Code != http.StatusOK {
+		t.Errorf("healthz status = %d, want 200", rec.Code)
+	}
+}
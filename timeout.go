@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+// middlewareTimeout cancels the request context after d so stuck queries
+// give up and free their connection. Streaming routes are exempt: they get
+// no deadline and have the server's write deadline lifted.
+func middlewareTimeout(d time.Duration, streaming bool, next http.Handler) http.Handler {
+	if streaming {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+				log.Printf("failed to lift the write deadline for %s: %v", r.URL.Path, err)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	if d <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
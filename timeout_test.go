@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/store"
+)
+
+func TestMiddlewareTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		streaming    bool
+		wantDeadline bool
+	}{
+		{"db-bound", time.Second, false, true},
+		{"streaming", time.Second, true, false},
+		{"disabled", 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deadline time.Time
+			var hasDeadline bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deadline, hasDeadline = r.Context().Deadline()
+			})
+
+			start := time.Now()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			middlewareTimeout(tt.timeout, tt.streaming, next).ServeHTTP(httptest.NewRecorder(), req)
+			end := time.Now()
+
+			if hasDeadline != tt.wantDeadline {
+				t.Fatalf("has deadline = %v, want %v", hasDeadline, tt.wantDeadline)
+			}
+			if hasDeadline && (deadline.Before(start.Add(tt.timeout)) || deadline.After(end.Add(tt.timeout))) {
+				t.Errorf("deadline is %v after the request started, want %v", deadline.Sub(start), tt.timeout)
+			}
+		})
+	}
+}
+
+func TestStreamingRouteOutlivesWriteTimeout(t *testing.T) {
+	const writeTimeout = 200 * time.Millisecond
+	stream := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+			http.NewResponseController(w).Flush()
+			time.Sleep(writeTimeout * 3 / 4)
+		}
+	}
+
+	for _, streaming := range []bool{true, false} {
+		cfg := newAPIConfig(testConfig(), store.NewMemory())
+		srv := httptest.NewUnstartedServer(cfg.mount([]route{
+			{pattern: "/stream", handler: stream, streaming: streaming, global: true},
+		}))
+		srv.Config.WriteTimeout = writeTimeout
+		srv.Start()
+
+		// Go through the compression and JSON case writers too
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/stream", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Accept", "application/json; case=camel")
+		var body []byte
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		srv.Close()
+
+		complete := err == nil && strings.Count(string(body), "line") == 3
+		if complete != streaming {
+			t.Errorf("streaming %v: got %q, %v; want complete %v", streaming, body, err, streaming)
+		}
+	}
+}
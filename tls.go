@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// autocertManager returns a Let's Encrypt manager for the configured hosts,
+// or nil when certificates come from files
+func autocertManager(c config.TLS) *autocert.Manager {
+	if len(c.AutocertDomains) == 0 {
+		return nil
+	}
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(c.AutocertDomains...),
+		Cache:      autocert.DirCache(c.AutocertCacheDir),
+		Email:      c.AutocertEmail,
+	}
+}
+
+// serverTLSConfig returns the tls.Config for the HTTPS server
+func serverTLSConfig(m *autocert.Manager) *tls.Config {
+	if m != nil {
+		return m.TLSConfig()
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+// grpcTLSConfig returns the tls.Config for the gRPC listener, with the same
+// certificate as the HTTPS server
+func grpcTLSConfig(c config.TLS, m *autocert.Manager) (*tls.Config, error) {
+	if m != nil {
+		return m.TLSConfig(), nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert}}, nil
+}
+
+// middlewareHSTS tells browsers to only use HTTPS for this host, and for
+// its subdomains if configured
+func middlewareHSTS(c config.TLS, next http.Handler) http.Handler {
+	value := fmt.Sprintf("max-age=%d", c.HSTSMaxAge)
+	if c.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", value)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// redirectToHTTPS sends plain HTTP requests to the same URL over HTTPS.
+// The redirect is a 308, so clients repeat the method and body.
+func redirectToHTTPS(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
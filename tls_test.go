@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hydeh3r3/chirpy/internal/config"
+)
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct {
+		httpsAddr string
+		method    string
+		target    string
+		want      string
+	}{
+		{":443", http.MethodGet, "http://example.com/api/chirps?limit=5", "https://example.com/api/chirps?limit=5"},
+		{":443", http.MethodGet, "http://example.com:8080/", "https://example.com/"},
+		{"", http.MethodGet, "http://example.com/", "https://example.com/"},
+		{":8443", http.MethodGet, "http://example.com:8080/app/", "https://example.com:8443/app/"},
+		{":8443", http.MethodPost, "http://[::1]/api/users", "https://[::1]:8443/api/users"},
+		{":443", http.MethodGet, "http://[::1]:8080/", "https://[::1]/"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		redirectToHTTPS(tt.httpsAddr).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != http.StatusPermanentRedirect {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, rec.Code, http.StatusPermanentRedirect)
+		}
+		if got := rec.Header().Get("Location"); got != tt.want {
+			t.Errorf("%s %s with HTTPS_ADDR %q: Location = %q, want %q", tt.method, tt.target, tt.httpsAddr, got, tt.want)
+		}
+	}
+}
+
+func TestMiddlewareHSTS(t *testing.T) {
+	tests := []struct {
+		c    config.TLS
+		want string
+	}{
+		{config.TLS{HSTSMaxAge: 63072000}, "max-age=63072000"},
+		{config.TLS{HSTSMaxAge: 300, HSTSIncludeSubdomains: true}, "max-age=300; includeSubDomains"},
+	}
+	for _, tt := range tests {
+		handler := middlewareHSTS(tt.c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://example.com/", nil))
+		if got := rec.Header().Get("Strict-Transport-Security"); got != tt.want {
+			t.Errorf("Strict-Transport-Security = %q, want %q", got, tt.want)
+		}
+	}
+}
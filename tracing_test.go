@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestTracingContinuesTraceparent(t *testing.T) {
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	srv := newTestServer(t, testConfig())
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	srv.do(http.MethodGet, "/api/chirps/00000000-0000-0000-0000-000000000001", "",
+		"Traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /api/chirps/{chirpID}" {
+		t.Errorf("name = %q", span.Name())
+	}
+	if got := span.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("trace ID = %s, want %s", got, traceID)
+	}
+	var status int64
+	for _, attr := range span.Attributes() {
+		if attr.Key == semconv.HTTPResponseStatusCodeKey {
+			status = attr.Value.AsInt64()
+		}
+	}
+	if status != http.StatusNotFound {
+		t.Errorf("status attribute = %d, want 404", status)
+	}
+}
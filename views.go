@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/request"
+
+	"github.com/google/uuid"
+)
+
+// viewRollupEvery is how often recorded views are added to the view counts
+const viewRollupEvery = time.Minute
+
+// Author view analytics windows
+const (
+	authorViewsDefaultDays = 30
+	authorViewsMaxDays     = 365
+)
+
+// chirpViews records chirp views and reads back their counts
+type chirpViews interface {
+	RecordChirpView(ctx context.Context, arg database.RecordChirpViewParams) (int64, error)
+	RollupChirpViews(ctx context.Context) (int64, error)
+	GetChirpViewCount(ctx context.Context, chirpID uuid.UUID) (int64, error)
+	GetChirpViewCounts(ctx context.Context, chirpIDs []uuid.UUID) ([]database.ChirpViewCount, error)
+	GetAuthorDailyViews(ctx context.Context, arg database.GetAuthorDailyViewsParams) ([]database.GetAuthorDailyViewsRow, error)
+}
+
+// authorViewsResponse summarizes views of an author's chirps
+type authorViewsResponse struct {
+	UserID      string        `json:"user_id"`
+	Days        int           `json:"days"`
+	TotalViews  int64         `json:"total_views"`
+	ViewsPerDay []periodCount `json:"views_per_day"`
+}
+
+// chirpViewer identifies a viewer for deduplication by client IP. A
+// user_id in the body isn't verified, so counting by it would let anyone
+// inflate a chirp's views with made-up IDs.
+func chirpViewer(r *http.Request) string {
+	return "ip:" + clientIP(r)
+}
+
+// recordViewHandler records a view of a chirp. Views are counted once per
+// client IP per day and show up in view_count and the author's daily views
+// after the next rollup. The optional body is ignored.
+func (cfg *apiConfig) recordViewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	chirpID, err := request.ParseUUIDParam(r, "chirpID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	// Make sure the chirp exists
+	_, err = cfg.getVisibleChirp(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Chirp not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get chirp"})
+		return
+	}
+
+	_, err = cfg.views.RecordChirpView(r.Context(), database.RecordChirpViewParams{
+		ChirpID: chirpID,
+		Viewer:  chirpViewer(r),
+		Day:     time.Now().UTC().Truncate(24 * time.Hour),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to record view"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// viewCount returns a chirp's rolled-up view count, or 0 without Postgres
+func (cfg *apiConfig) viewCount(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	if cfg.views == nil {
+		return 0, nil
+	}
+	return cfg.views.GetChirpViewCount(ctx, chirpID)
+}
+
+// viewCounts returns the view counts for chirpIDs, keyed by chirp
+func (cfg *apiConfig) viewCounts(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	if cfg.views == nil {
+		return nil, nil
+	}
+	rows, err := cfg.views.GetChirpViewCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ChirpID] = row.ViewCount
+	}
+	return counts, nil
+}
+
+// rollupChirpViews periodically adds newly recorded views to the per-chirp
+// and daily view counts until ctx is done
+func (cfg *apiConfig) rollupChirpViews(ctx context.Context) {
+	ticker := time.NewTicker(viewRollupEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := cfg.views.RollupChirpViews(ctx); err != nil {
+			log.Printf("failed to roll up chirp views: %v", err)
+		}
+	}
+}
+
+// authorViewsHandler reports daily views of a user's chirps over the last
+// days days. Only users and chirps in the request's tenant count. It is
+// public on purpose: it only adds up view counts every chirp already
+// shows, and says nothing about who viewed.
+func (cfg *apiConfig) authorViewsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := request.ParseUUIDParam(r, "userID")
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+	days, err := request.ParseInt(r, "days", authorViewsDefaultDays, 1, authorViewsMaxDays)
+	if err != nil {
+		respondWithRequestError(w, err)
+		return
+	}
+
+	tenant := tenantID(r.Context())
+	_, err = cfg.store.GetUser(r.Context(), database.GetUserParams{ID: userID, TenantID: tenant})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: "User not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get user"})
+		return
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	rows, err := cfg.views.GetAuthorDailyViews(r.Context(), database.GetAuthorDailyViewsParams{
+		UserID:   userID,
+		TenantID: tenant,
+		Day:      since,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: "Failed to get views"})
+		return
+	}
+
+	resp := authorViewsResponse{
+		UserID:      userID.String(),
+		Days:        days,
+		ViewsPerDay: []periodCount{},
+	}
+	for _, row := range rows {
+		resp.TotalViews += row.Views
+		resp.ViewsPerDay = append(resp.ViewsPerDay, periodCount{Period: row.Day, Value: row.Views})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hydeh3r3/chirpy/internal/database"
+	"github.com/hydeh3r3/chirpy/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// memoryViews keeps chirp views in memory the way the Postgres queries do
+type memoryViews struct {
+	mu     sync.Mutex
+	store  store.Store
+	views  map[database.RecordChirpViewParams]bool // counted by a rollup
+	counts map[uuid.UUID]int64
+	daily  map[uuid.UUID]map[time.Time]int64 // chirp ID to day to views
+}
+
+func newMemoryViews(st store.Store) *memoryViews {
+	return &memoryViews{
+		store:  st,
+		views:  map[database.RecordChirpViewParams]bool{},
+		counts: map[uuid.UUID]int64{},
+		daily:  map[uuid.UUID]map[time.Time]int64{},
+	}
+}
+
+func (m *memoryViews) RecordChirpView(ctx context.Context, arg database.RecordChirpViewParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.views[arg]; ok {
+		return 0, nil
+	}
+	m.views[arg] = false
+	return 1, nil
+}
+
+func (m *memoryViews) RollupChirpViews(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for view, counted := range m.views {
+		if !counted {
+			m.views[view] = true
+			m.counts[view.ChirpID]++
+			if m.daily[view.ChirpID] == nil {
+				m.daily[view.ChirpID] = map[time.Time]int64{}
+			}
+			m.daily[view.ChirpID][view.Day]++
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memoryViews) GetChirpViewCount(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[chirpID], nil
+}
+
+func (m *memoryViews) GetChirpViewCounts(ctx context.Context, chirpIDs []uuid.UUID) ([]database.ChirpViewCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rows []database.ChirpViewCount
+	for _, id := range chirpIDs {
+		if n, ok := m.counts[id]; ok {
+			rows = append(rows, database.ChirpViewCount{ChirpID: id, ViewCount: n})
+		}
+	}
+	return rows, nil
+}
+
+func (m *memoryViews) GetAuthorDailyViews(ctx context.Context, arg database.GetAuthorDailyViewsParams) ([]database.GetAuthorDailyViewsRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	perDay := map[time.Time]int64{}
+	for chirpID, days := range m.daily {
+		chirp, err := m.store.GetChirp(ctx, database.GetChirpParams{ID: chirpID, TenantID: arg.TenantID})
+		if err != nil || chirp.UserID != arg.UserID {
+			continue
+		}
+		for day, views := range days {
+			if !day.Before(arg.Day) {
+				perDay[day] += views
+			}
+		}
+	}
+	var rows []database.GetAuthorDailyViewsRow
+	for day, views := range perDay {
+		rows = append(rows, database.GetAuthorDailyViewsRow{Day: day, Views: views})
+	}
+	slices.SortFunc(rows, func(a, b database.GetAuthorDailyViewsRow) int { return a.Day.Compare(b.Day) })
+	return rows, nil
+}
+
+// newViewsTestServer serves the view routes with views kept in memory
+func newViewsTestServer(t *testing.T) (*testServer, *memoryViews) {
+	st := store.NewMemory()
+	cfg := newAPIConfig(testConfig(), st)
+	cfg.views = newMemoryViews(st)
+	handler := cfg.mount([]route{
+		{pattern: "/api/users", handler: cfg.createUserHandler},
+		{pattern: "/api/chirps", handler: cfg.createChirpHandler},
+		{pattern: "/api/chirps/{chirpID}", handler: cfg.getChirpHandler},
+		{pattern: "/api/chirps/{chirpID}/view", handler: cfg.recordViewHandler},
+		{pattern: "/api/users/{userID}/analytics/views", handler: cfg.authorViewsHandler},
+	})
+	return &testServer{t: t, handler: handler, store: st}, cfg.views.(*memoryViews)
+}
+
+// view records a view of a chirp from a client address
+func (s *testServer) view(chirpID, remoteAddr, body string) int {
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirpID+"/view", nil)
+	if body != "" {
+		req = httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirpID+"/view", strings.NewReader(body))
+	}
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestViewsAreCountedOncePerClient(t *testing.T) {
+	srv, views := newViewsTestServer(t)
+	author := srv.createUser("author@example.com")
+	chirp := srv.createChirp(author.ID, "watch this")
+
+	// Made-up user IDs from one address count once
+	for i := 0; i < 5; i++ {
+		body := `{"user_id":"` + uuid.NewString() + `"}`
+		if code := srv.view(chirp.ID, "203.0.113.7:1234", body); code != http.StatusNoContent {
+			t.Fatalf("status = %d, want 204", code)
+		}
+	}
+	srv.view(chirp.ID, "203.0.113.7:5678", "")
+	srv.view(chirp.ID, "198.51.100.2:1234", "")
+
+	if got := decode[chirpResponse](t, srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, "")); got.ViewCount != 0 {
+		t.Errorf("view count before rollup = %d, want 0", got.ViewCount)
+	}
+	views.RollupChirpViews(context.Background())
+	if got := decode[chirpResponse](t, srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, "")); got.ViewCount != 2 {
+		t.Errorf("view count = %d, want 2", got.ViewCount)
+	}
+
+	// A second rollup adds nothing new
+	views.RollupChirpViews(context.Background())
+	if got := decode[chirpResponse](t, srv.do(http.MethodGet, "/api/chirps/"+chirp.ID, "")); got.ViewCount != 2 {
+		t.Errorf("view count after a second rollup = %d, want 2", got.ViewCount)
+	}
+
+	if code := srv.view(uuid.NewString(), "203.0.113.7:1234", ""); code != http.StatusNotFound {
+		t.Errorf("unknown chirp: status = %d, want 404", code)
+	}
+}
+
+func TestAuthorViews(t *testing.T) {
+	srv, views := newViewsTestServer(t)
+	author := srv.createUser("author@example.com")
+	other := srv.createUser("other@example.com")
+	first := srv.createChirp(author.ID, "first")
+	second := srv.createChirp(author.ID, "second")
+	elsewhere := srv.createChirp(other.ID, "not mine")
+
+	srv.view(first.ID, "203.0.113.7:1", "")
+	srv.view(first.ID, "198.51.100.2:1", "")
+	srv.view(second.ID, "203.0.113.7:1", "")
+	srv.view(elsewhere.ID, "203.0.113.7:1", "")
+
+	// Views only reach the daily counts with a rollup
+	rec := srv.do(http.MethodGet, "/api/users/"+author.ID+"/analytics/views?days=7", "")
+	if got := decode[authorViewsResponse](t, rec); got.TotalViews != 0 {
+		t.Errorf("views before rollup = %d, want 0", got.TotalViews)
+	}
+	views.RollupChirpViews(context.Background())
+
+	rec = srv.do(http.MethodGet, "/api/users/"+author.ID+"/analytics/views?days=7", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	got := decode[authorViewsResponse](t, rec)
+	if got.TotalViews != 3 || got.Days != 7 || len(got.ViewsPerDay) != 1 {
+		t.Errorf("got %+v, want 3 views on one day", got)
+	}
+
+	if rec := srv.do(http.MethodGet, "/api/users/"+uuid.NewString()+"/analytics/views", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown user: status = %d, want 404", rec.Code)
+	}
+	if rec := srv.do(http.MethodGet, "/api/users/"+author.ID+"/analytics/views?days=0", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("days=0: status = %d, want 400", rec.Code)
+	}
+}
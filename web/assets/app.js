@@ -0,0 +1,188 @@
+// Chirpy web client. There are no passwords yet, so signing up makes the
+// new user the current user of this browser, and the home feed shows the
+// chirps posted from here, refreshed from the API.
+"use strict";
+
+const maxChirpLength = 140;
+const feedSize = 100;
+
+const state = {
+    user: JSON.parse(localStorage.getItem("chirpy.user") || "null"),
+    chirpIDs: JSON.parse(localStorage.getItem("chirpy.chirps") || "[]"),
+};
+
+function save() {
+    localStorage.setItem("chirpy.user", JSON.stringify(state.user));
+    localStorage.setItem("chirpy.chirps", JSON.stringify(state.chirpIDs));
+}
+
+// api calls the JSON API and throws the server's error message on failure
+async function api(method, path, body, headers = {}) {
+    const resp = await fetch(path, {
+        method,
+        headers: body ? { "Content-Type": "application/json", ...headers } : headers,
+        body: body ? JSON.stringify(body) : undefined,
+    });
+    const data = resp.status === 204 ? null : await resp.json().catch(() => null);
+    if (!resp.ok) {
+        const msg = data && data.error ? data.error : resp.statusText;
+        throw new Error(data && data.field ? `${data.field} ${msg}` : msg);
+    }
+    return data;
+}
+
+function showError(err) {
+    const el = document.getElementById("error");
+    el.textContent = err ? err.message : "";
+    el.hidden = !err;
+}
+
+function show(id) {
+    for (const section of document.querySelectorAll("main > section")) {
+        section.hidden = section.id !== id;
+    }
+}
+
+function renderChirp(chirp) {
+    const li = document.createElement("li");
+    li.className = "chirp";
+
+    const body = document.createElement("p");
+    body.textContent = chirp.body;
+    li.append(body);
+
+    const meta = document.createElement("a");
+    meta.href = `/app/chirps/${chirp.id}`;
+    meta.dataset.link = "";
+    meta.className = "meta";
+    const parts = [new Date(chirp.created_at).toLocaleString()];
+    if (chirp.rechirp_count) parts.push(`${chirp.rechirp_count} rechirps`);
+    if (chirp.view_count) parts.push(`${chirp.view_count} views`);
+    meta.textContent = parts.join(" · ");
+    li.append(meta);
+
+    for (const preview of chirp.link_previews || []) {
+        const a = document.createElement("a");
+        a.href = preview.url;
+        a.className = "preview";
+        a.textContent = preview.title || preview.url;
+        li.append(a);
+    }
+    if (chirp.pending) {
+        li.append(note("Held for review"));
+    }
+    if (chirp.warning) {
+        li.append(note(chirp.warning));
+    }
+    return li;
+}
+
+function note(text) {
+    const p = document.createElement("p");
+    p.className = "note";
+    p.textContent = text;
+    return p;
+}
+
+async function renderHome() {
+    show("home");
+    const feed = document.getElementById("feed");
+    if (state.chirpIDs.length === 0) {
+        feed.replaceChildren(note("Nothing here yet. Post your first chirp!"));
+        return;
+    }
+
+    const { chirps } = await api("POST", "/api/chirps/batch", { ids: state.chirpIDs });
+    feed.replaceChildren(...chirps.filter(Boolean).map(renderChirp));
+}
+
+async function renderChirpPage(id) {
+    show("chirp");
+    const chirp = await api("GET", `/api/chirps/${encodeURIComponent(id)}`);
+    document.getElementById("single").replaceChildren(renderChirp(chirp));
+}
+
+// route renders the page for the current URL
+async function route() {
+    showError(null);
+    document.getElementById("whoami").textContent = state.user ? state.user.email : "";
+    if (!state.user) {
+        show("signup");
+        return;
+    }
+
+    const match = location.pathname.match(/^\/app\/chirps\/([^/]+)$/);
+    try {
+        if (match) {
+            await renderChirpPage(match[1]);
+        } else {
+            await renderHome();
+        }
+    } catch (err) {
+        showError(err);
+    }
+}
+
+function navigate(path) {
+    history.pushState(null, "", path);
+    route();
+}
+
+document.addEventListener("click", (event) => {
+    const link = event.target.closest("a[data-link]");
+    if (link && !event.metaKey && !event.ctrlKey) {
+        event.preventDefault();
+        navigate(link.getAttribute("href"));
+    }
+});
+window.addEventListener("popstate", route);
+
+document.getElementById("signup-form").addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const form = event.target;
+    try {
+        state.user = await api("POST", "/api/users", { email: form.email.value });
+        state.chirpIDs = [];
+        save();
+        navigate("/app/");
+    } catch (err) {
+        showError(err);
+    }
+});
+
+const chirpForm = document.getElementById("chirp-form");
+chirpForm.body.addEventListener("input", () => {
+    const left = maxChirpLength - [...chirpForm.body.value].length;
+    const counter = document.getElementById("counter");
+    counter.textContent = left;
+    counter.classList.toggle("over", left < 0);
+});
+chirpForm.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    try {
+        // The key makes a retried submit safe where the server supports it
+        const chirp = await api(
+            "POST", "/api/chirps",
+            { body: chirpForm.body.value, user_id: state.user.id },
+            { "Idempotency-Key": crypto.randomUUID() },
+        );
+        state.chirpIDs = [chirp.id, ...state.chirpIDs].slice(0, feedSize);
+        save();
+        chirpForm.reset();
+        document.getElementById("counter").textContent = maxChirpLength;
+        await renderHome();
+
+        // Held chirps are left out of the feed, and warnings are only on
+        // the create response, so show them once here
+        const feed = document.getElementById("feed");
+        if (chirp.pending) {
+            feed.prepend(renderChirp(chirp));
+        } else if (chirp.warning && feed.firstChild) {
+            feed.firstChild.replaceWith(renderChirp(chirp));
+        }
+    } catch (err) {
+        showError(err);
+    }
+});
+
+route();